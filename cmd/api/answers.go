@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/theluminousartemis/inkspire/internal/badges"
+	"github.com/theluminousartemis/inkspire/internal/mailer"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+// AcceptAnswer godoc
+//
+//	@Summary		Accepts an answer
+//	@Description	Marks a top-level comment as the accepted answer of the post, only the post author can accept an answer
+//	@Tags			posts, comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID		path		int		true	"Post ID"
+//	@Param			commentID	path		int		true	"Comment ID"
+//	@Success		204			{string}	string	"Answer accepted"
+//	@Failure		400			{object}	error
+//	@Failure		403			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID}/accept [put]
+func (app *application) acceptAnswerHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	comment := getCommentfromCtx(r)
+	user := getUserFromCtx(r)
+
+	if comment.PostID != post.ID || comment.Deleted {
+		app.commentNotFoundErrorResponse(w, r, store.ErrNotFound)
+		return
+	}
+
+	if post.UserID != user.ID {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	if comment.ParentID != nil {
+		app.badRequestError(w, r, errors.New("only top-level comments can be accepted as an answer"))
+		return
+	}
+
+	if post.AcceptedCommentID != nil && *post.AcceptedCommentID == comment.ID {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	events, err := app.storage.Posts.SetAcceptedAnswer(r.Context(), post.ID, &comment.ID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.postNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.invalidateReputation(r.Context(), events)
+	app.badges.Emit(badges.EventAnswerAccepted, comment.UserID)
+
+	if comment.UserID != user.ID {
+		go app.notifyAnswerAccepted(post, comment, user)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnacceptAnswer godoc
+//
+//	@Summary		Unaccepts an answer
+//	@Description	Clears the accepted answer of the post, only the post author can unaccept an answer
+//	@Tags			posts, comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID		path		int		true	"Post ID"
+//	@Param			commentID	path		int		true	"Comment ID"
+//	@Success		204			{string}	string	"Answer unaccepted"
+//	@Failure		403			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID}/accept [delete]
+func (app *application) unacceptAnswerHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	comment := getCommentfromCtx(r)
+	user := getUserFromCtx(r)
+
+	if post.UserID != user.ID {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	if post.AcceptedCommentID == nil || *post.AcceptedCommentID != comment.ID {
+		app.commentNotFoundErrorResponse(w, r, errors.New("comment is not the accepted answer"))
+		return
+	}
+
+	events, err := app.storage.Posts.SetAcceptedAnswer(r.Context(), post.ID, nil)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.postNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.invalidateReputation(r.Context(), events)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) invalidateReputation(ctx context.Context, events []store.ReputationEvent) {
+	for _, e := range events {
+		app.invalidateUser(ctx, e.UserID)
+	}
+}
+
+// notifyAnswerAccepted emails the author of the accepted answer. It runs
+// outside of the request so failures are only logged.
+func (app *application) notifyAnswerAccepted(post *store.Post, comment *store.Comment, acceptedBy *store.User) {
+	author, err := app.storage.Users.GetByID(context.Background(), comment.UserID)
+	if err != nil {
+		app.l.Errorw("error fetching answer author", "userID", comment.UserID, "error", err)
+		return
+	}
+
+	vars := struct {
+		Username   string
+		AcceptedBy string
+		PostTitle  string
+		PostURL    string
+	}{
+		Username:   author.Username,
+		AcceptedBy: acceptedBy.Username,
+		PostTitle:  post.Title,
+		PostURL:    fmt.Sprintf("%s/posts/%d", app.config.frontendURL, post.ID),
+	}
+
+	isProdEnv := app.config.env == "production"
+	status, err := app.mailer.Send(mailer.AnswerAcceptedTemplate, author.Username, author.Email, vars, isProdEnv)
+	if err != nil {
+		app.l.Errorw("error sending answer accepted email", "userID", author.ID, "error", err)
+		return
+	}
+	app.l.Infow("Email sent", "status code", status)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+func TestAcceptAnswer(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	posts := app.storage.Posts.(*store.MockPostStore)
+
+	acceptedID := func(t *testing.T) *int64 {
+		t.Helper()
+		var post store.Post
+		readData(t, client.do(t, http.MethodGet, "/v1/posts/1", ""), http.StatusOK, &post)
+		return post.AcceptedCommentID
+	}
+
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodPut, "/v1/posts/1/comments/1/accept", "").Code)
+	if posts.Accepted[1] != 1 {
+		t.Errorf("got accepted answers %v, want comment 1 on post 1", posts.Accepted)
+	}
+	if got := acceptedID(t); got == nil || *got != 1 {
+		t.Errorf("got accepted_comment_id %v, want 1", got)
+	}
+
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodPut, "/v1/posts/1/comments/1/accept", "").Code)
+	if posts.Accepted[1] != 1 {
+		t.Errorf("got accepted answers %v after accepting twice, want comment 1 on post 1", posts.Accepted)
+	}
+
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodDelete, "/v1/posts/1/comments/1/accept", "").Code)
+	if _, ok := posts.Accepted[1]; ok {
+		t.Errorf("got accepted answers %v, want none on post 1", posts.Accepted)
+	}
+	if got := acceptedID(t); got != nil {
+		t.Errorf("got accepted_comment_id %d, want none", *got)
+	}
+
+	checkResponseCode(t, http.StatusNotFound, client.do(t, http.MethodDelete, "/v1/posts/1/comments/1/accept", "").Code)
+}
+
+func TestAcceptAnswerErrors(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	posts := app.storage.Posts.(*store.MockPostStore)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"refuses replies", http.MethodPut, "/v1/posts/1/comments/2/accept", http.StatusBadRequest},
+		{"refuses answers of another post", http.MethodPut, "/v1/posts/2/comments/1/accept", http.StatusNotFound},
+		{"refuses users other than the post author", http.MethodPut, "/v1/posts/2/comments/3/accept", http.StatusForbidden},
+		{"refuses missing comments", http.MethodPut, "/v1/posts/1/comments/9/accept", http.StatusNotFound},
+		{"unaccepts only the accepted answer", http.MethodDelete, "/v1/posts/1/comments/1/accept", http.StatusNotFound},
+		{"unaccepts only on own posts", http.MethodDelete, "/v1/posts/2/comments/3/accept", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponseCode(t, tt.want, client.do(t, tt.method, tt.path, "").Code)
+		})
+	}
+	if len(posts.Accepted) != 0 {
+		t.Errorf("got accepted answers %v, want none", posts.Accepted)
+	}
+}
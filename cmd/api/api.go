@@ -17,10 +17,14 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 	"github.com/theluminousartemis/inkspire/docs"
 	"github.com/theluminousartemis/inkspire/internal/auth"
+	"github.com/theluminousartemis/inkspire/internal/badges"
 	"github.com/theluminousartemis/inkspire/internal/mailer"
+	"github.com/theluminousartemis/inkspire/internal/ranking"
 	"github.com/theluminousartemis/inkspire/internal/ratelimiter"
+	"github.com/theluminousartemis/inkspire/internal/search"
 	"github.com/theluminousartemis/inkspire/internal/store"
 	"github.com/theluminousartemis/inkspire/internal/store/cache"
+	"github.com/theluminousartemis/inkspire/internal/timeline"
 	"go.uber.org/zap"
 )
 
@@ -32,6 +36,9 @@ type application struct {
 	authenticator auth.Authenticator
 	cache         cache.Storage
 	rateLimiter   ratelimiter.Limiter
+	badges        *badges.Engine
+	search        search.Searcher
+	timelines     *timeline.Service
 }
 
 type config struct {
@@ -44,6 +51,61 @@ type config struct {
 	auth        authConfig
 	redisCfg    redisConfig
 	ratelimiter ratelimiter.Config
+	reputation  reputationConfig
+	bounty      bountyConfig
+	moderation  moderationConfig
+	reactions   []string
+	explore     exploreConfig
+	feed        feedConfig
+	comments    commentsConfig
+	search      searchConfig
+}
+
+// searchConfig selects the search backend, postgres or the embedded index
+// stored in indexPath.
+type searchConfig struct {
+	backend   string
+	indexPath string
+}
+
+// commentsConfig holds how long after posting a comment can still be edited,
+// by role of the editor, a zero window never closes. maxDepth bounds the
+// nesting of replies on posts without a limit of their own.
+type commentsConfig struct {
+	editWindows map[string]time.Duration
+	maxDepth    int
+}
+
+// feedConfig tunes the ranked feed: the newest rankedCandidates posts of the
+// feed are scored with the weights.
+type feedConfig struct {
+	rankedCandidates int
+	rankingWeights   ranking.Weights
+}
+
+// exploreConfig tunes the trending ranking: posts from the last trendingWindow
+// are scored by votes and comments decayed by age^trendingGravity, and the top
+// trendingSize are cached every trendingInterval.
+type exploreConfig struct {
+	trendingInterval time.Duration
+	trendingWindow   time.Duration
+	trendingGravity  float64
+	trendingSize     int
+}
+
+type moderationConfig struct {
+	closeVotes int
+}
+
+type bountyConfig struct {
+	duration      time.Duration
+	checkInterval time.Duration
+}
+
+// reputationConfig holds the reputation needed to unlock each privilege
+type reputationConfig struct {
+	downvote  int
+	editPosts int
 }
 
 type redisConfig struct {
@@ -108,6 +170,13 @@ func (app *application) mount() *chi.Mux {
 		r.With(app.BasicAuthMiddleware()).Get("/metrics", expvar.Handler().ServeHTTP)
 		docsUrl := fmt.Sprintf("%s/swagger/doc.json", app.config.addr)
 		r.Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL(docsUrl)))
+		r.With(app.OptionalAuthTokenMiddleware).Get("/explore", app.exploreHandler)
+		r.Get("/search", app.searchHandler)
+		r.Route("/feeds", func(r chi.Router) {
+			r.Get("/latest/{format}", app.latestFeedHandler)
+			r.Get("/users/{userID}/{format}", app.userPostsFeedHandler)
+			r.With(app.tagsContextMiddleware).Get("/tags/{tag}/{format}", app.tagPostsFeedHandler)
+		})
 		r.Route("/posts", func(r chi.Router) {
 			r.Use(app.AuthTokenMiddleware)
 			r.Post("/", app.createPostHandler)
@@ -115,12 +184,33 @@ func (app *application) mount() *chi.Mux {
 				r.Use(app.postsContextMiddleware)
 				r.Get("/", app.getPostHandler)
 				r.Delete("/", app.checkPostOwnership("admin", app.deletePostHandler))
-				r.Patch("/", app.checkPostOwnership("moderator", app.updatePostHandler))
+				r.Patch("/", app.checkPostEditPermission(app.updatePostHandler))
+				r.Put("/vote", app.votePostHandler)
+				r.Get("/bounty", app.getBountyHandler)
+				r.Post("/bounty", app.createBountyHandler)
+				r.Put("/close", app.checkRole("moderator", app.closePostHandler))
+				r.Put("/comment-settings", app.checkPostOwnership("moderator", app.updateCommentSettingsHandler))
+				r.Put("/reopen", app.checkRole("moderator", app.reopenPostHandler))
+				r.Put("/close-vote", app.closeVoteHandler)
+				r.Put("/bookmark", app.bookmarkPostHandler)
+				r.Delete("/bookmark", app.removeBookmarkHandler)
+				r.Get("/related", app.getRelatedPostsHandler)
+				r.Get("/reactions", app.listPostReactionsHandler)
+				r.Post("/reactions", app.togglePostReactionHandler)
 				r.Route("/comments", func(r chi.Router) {
+					r.Get("/", app.listCommentsHandler)
 					r.Post("/", app.createCommentHandler)
 					r.Route("/{commentID}", func(r chi.Router) {
 						r.Use(app.commentsContextMiddleware)
+						r.Get("/", app.getCommentHandler)
 						r.Delete("/", app.checkcommentOwnership("admin", app.deleteCommentHandler))
+						r.Patch("/", app.checkcommentOwnership("moderator", app.updateCommentHandler))
+						r.Get("/history", app.checkcommentOwnership("moderator", app.listCommentEditsHandler))
+						r.Put("/vote", app.voteCommentHandler)
+						r.Put("/accept", app.acceptAnswerHandler)
+						r.Delete("/accept", app.unacceptAnswerHandler)
+						r.Get("/reactions", app.listCommentReactionsHandler)
+						r.Post("/reactions", app.toggleCommentReactionHandler)
 					})
 				})
 			})
@@ -138,9 +228,44 @@ func (app *application) mount() *chi.Mux {
 			r.Group(func(r chi.Router) {
 				r.Use(app.AuthTokenMiddleware)
 				r.Get("/feed", app.getUserFeedHandler)
+				r.Get("/bookmarks", app.listBookmarksHandler)
+				r.Get("/bookmarks/collections", app.listBookmarkCollectionsHandler)
+				r.Get("/tags", app.listTagSubscriptionsHandler)
+				r.Route("/notifications", func(r chi.Router) {
+					r.Get("/", app.listNotificationsHandler)
+					r.Get("/unread", app.countUnreadNotificationsHandler)
+					r.Put("/read", app.markAllNotificationsReadHandler)
+					r.Put("/{notificationID}/read", app.markNotificationReadHandler)
+				})
 			})
 		})
 
+		r.Route("/tags", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Get("/", app.listTagsHandler)
+			r.Route("/{tag}", func(r chi.Router) {
+				r.Use(app.tagsContextMiddleware)
+				r.Get("/", app.getTagHandler)
+				r.Get("/posts", app.getTagPostsHandler)
+				r.Put("/follow", app.followTagHandler)
+				r.Put("/unfollow", app.unfollowTagHandler)
+				r.Put("/ignore", app.ignoreTagHandler)
+				r.Put("/unignore", app.unignoreTagHandler)
+				r.Patch("/", app.checkRole("moderator", app.updateTagHandler))
+				r.Post("/synonyms", app.checkRole("moderator", app.addTagSynonymHandler))
+				r.Delete("/synonyms/{synonym}", app.checkRole("moderator", app.removeTagSynonymHandler))
+				r.Put("/rename", app.checkRole("moderator", app.renameTagHandler))
+				r.Put("/merge", app.checkRole("moderator", app.mergeTagHandler))
+			})
+		})
+
+		r.Route("/badges", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Get("/", app.listBadgesHandler)
+			r.Post("/", app.checkRole("admin", app.createBadgeHandler))
+			r.Delete("/{badgeID}", app.checkRole("admin", app.deleteBadgeHandler))
+		})
+
 		//users auth & registration
 		r.Route("/authentication", func(r chi.Router) {
 			r.Post("/token", app.createTokenHandler)
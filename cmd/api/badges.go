@@ -0,0 +1,122 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+type CreateBadgePayload struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+	Metric      string `json:"metric" validate:"required,oneof=posts comments accepted_answers followers reputation streak_days"`
+	Threshold   int    `json:"threshold" validate:"required,gte=1"`
+}
+
+// ListBadges godoc
+//
+//	@Summary		Lists badges
+//	@Description	Lists the badge rules that can be awarded
+//	@Tags			badges
+//	@Produce		json
+//	@Success		200	{object}	[]store.Badge
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/badges [get]
+func (app *application) listBadgesHandler(w http.ResponseWriter, r *http.Request) {
+	badges, err := app.storage.Badges.GetAll(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, badges); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// CreateBadge godoc
+//
+//	@Summary		Creates a badge
+//	@Description	Creates a badge rule awarded once the metric reaches the threshold, admin only
+//	@Tags			badges
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateBadgePayload	true	"Badge payload"
+//	@Success		201		{object}	store.Badge
+//	@Failure		400		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		409		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/badges [post]
+func (app *application) createBadgeHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateBadgePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	badge := &store.Badge{
+		Name:        payload.Name,
+		Description: payload.Description,
+		Metric:      payload.Metric,
+		Threshold:   payload.Threshold,
+	}
+
+	if err := app.storage.Badges.Create(r.Context(), badge); err != nil {
+		switch err {
+		case store.ErrConflict:
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, badge); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// DeleteBadge godoc
+//
+//	@Summary		Deletes a badge
+//	@Description	Deletes a badge rule and revokes it from the users it was awarded to, admin only
+//	@Tags			badges
+//	@Produce		json
+//	@Param			badgeID	path		int		true	"Badge ID"
+//	@Success		204		{string}	string	"Badge deleted"
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/badges/{badgeID} [delete]
+func (app *application) deleteBadgeHandler(w http.ResponseWriter, r *http.Request) {
+	badgeID, err := strconv.ParseInt(chi.URLParam(r, "badgeID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.storage.Badges.Delete(r.Context(), badgeID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.badgeNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
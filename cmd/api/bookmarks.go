@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+type BookmarkPayload struct {
+	Collection *string `json:"collection" validate:"omitempty,min=1,max=100"`
+}
+
+// BookmarkPost godoc
+//
+//	@Summary		Bookmarks a post
+//	@Description	Saves a post for later, optionally in a named collection which is created if needed
+//	@Tags			posts, bookmarks
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int				true	"Post ID"
+//	@Param			payload	body		BookmarkPayload	false	"Bookmark payload"
+//	@Success		200		{object}	store.Bookmark
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/bookmark [put]
+func (app *application) bookmarkPostHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+
+	//the payload is optional, an empty body bookmarks the post without a collection
+	var payload BookmarkPayload
+	if err := readJSON(w, r, &payload); err != nil && !errors.Is(err, io.EOF) {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	bookmark := &store.Bookmark{
+		UserID:     user.ID,
+		PostID:     post.ID,
+		Collection: payload.Collection,
+	}
+
+	if err := app.storage.Bookmarks.Add(r.Context(), bookmark); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, bookmark); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// RemoveBookmark godoc
+//
+//	@Summary		Removes a bookmark
+//	@Description	Removes a post from the user's bookmarks
+//	@Tags			posts, bookmarks
+//	@Produce		json
+//	@Param			postID	path		int		true	"Post ID"
+//	@Success		204		{string}	string	"Bookmark removed"
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/bookmark [delete]
+func (app *application) removeBookmarkHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+
+	if err := app.storage.Bookmarks.Remove(r.Context(), user.ID, post.ID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.bookmarkNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListBookmarks godoc
+//
+//	@Summary		Lists bookmarks
+//	@Description	Lists the authenticated user's bookmarks newest first, use next_cursor to fetch the following page
+//	@Tags			bookmarks
+//	@Produce		json
+//	@Param			limit		query		int		false	"Limit"
+//	@Param			cursor		query		string	false	"Cursor"
+//	@Param			collection	query		string	false	"Collection"
+//	@Success		200			{object}	[]store.Bookmark
+//	@Failure		400			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/bookmarks [get]
+func (app *application) listBookmarksHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+
+	bq := store.BookmarkQuery{
+		Limit: 20,
+	}
+
+	bq, err := bq.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(bq); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	bookmarks, next, err := app.storage.Bookmarks.GetByUserID(r.Context(), user.ID, bq)
+	if err != nil {
+		switch err {
+		case store.ErrInvalidCursor:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, bookmarks, next); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// ListBookmarkCollections godoc
+//
+//	@Summary		Lists bookmark collections
+//	@Description	Lists the authenticated user's bookmark collections with their bookmark counts
+//	@Tags			bookmarks
+//	@Produce		json
+//	@Success		200	{object}	[]store.BookmarkCollection
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/bookmarks/collections [get]
+func (app *application) listBookmarkCollectionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+
+	collections, err := app.storage.Bookmarks.GetCollections(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, collections); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
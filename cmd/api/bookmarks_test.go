@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+func TestBookmarks(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	bookmarks := app.storage.Bookmarks.(*store.MockBookmarkStore)
+
+	var bookmark store.Bookmark
+	readData(t, client.do(t, http.MethodPut, "/v1/posts/1/bookmark", ""), http.StatusOK, &bookmark)
+	if bookmark.UserID != 1 || bookmark.PostID != 1 || bookmark.Collection != nil {
+		t.Errorf("got %+v, want post 1 bookmarked by user 1 without a collection", bookmark)
+	}
+	readData(t, client.do(t, http.MethodPut, "/v1/posts/2/bookmark", `{"collection": "later"}`), http.StatusOK, &bookmark)
+	readData(t, client.do(t, http.MethodPut, "/v1/posts/1/bookmark", `{"collection": "go"}`), http.StatusOK, &bookmark)
+	if len(bookmarks.Bookmarks) != 2 || bookmark.ID != 1 || *bookmark.Collection != "go" {
+		t.Errorf("got %+v, want bookmarking post 1 again to move it to go", bookmarks.Bookmarks)
+	}
+
+	var post store.Post
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/2", ""), http.StatusOK, &post)
+	if !post.Bookmarked {
+		t.Error("got post 2 not bookmarked, want bookmarked")
+	}
+
+	var page []store.Bookmark
+	next := readData(t, client.do(t, http.MethodGet, "/v1/users/bookmarks?limit=1", ""), http.StatusOK, &page)
+	if len(page) != 1 || page[0].PostID != 2 || next == "" {
+		t.Fatalf("got %+v and cursor %q, want post 2 first with a cursor", page, next)
+	}
+	next = readData(t, client.do(t, http.MethodGet, "/v1/users/bookmarks?limit=1&cursor="+next, ""), http.StatusOK, &page)
+	if len(page) != 1 || page[0].PostID != 1 || next != "" {
+		t.Errorf("got %+v and cursor %q, want post 1 on the last page", page, next)
+	}
+
+	readData(t, client.do(t, http.MethodGet, "/v1/users/bookmarks?collection=later", ""), http.StatusOK, &page)
+	if len(page) != 1 || page[0].PostID != 2 {
+		t.Errorf("got %+v, want the bookmarks of later", page)
+	}
+
+	var collections []store.BookmarkCollection
+	readData(t, client.do(t, http.MethodGet, "/v1/users/bookmarks/collections", ""), http.StatusOK, &collections)
+	if len(collections) != 2 || collections[0].Name != "go" || collections[0].Count != 1 || collections[1].Name != "later" {
+		t.Errorf("got collections %+v, want go and later with a bookmark each", collections)
+	}
+
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodDelete, "/v1/posts/2/bookmark", "").Code)
+	if len(bookmarks.Bookmarks) != 1 || bookmarks.Bookmarks[0].PostID != 1 {
+		t.Errorf("got %+v, want only the bookmark of post 1 left", bookmarks.Bookmarks)
+	}
+	checkResponseCode(t, http.StatusNotFound, client.do(t, http.MethodDelete, "/v1/posts/2/bookmark", "").Code)
+}
+
+func TestBookmarkErrors(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	bookmarks := app.storage.Bookmarks.(*store.MockBookmarkStore)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"refuses an empty collection", http.MethodPut, "/v1/posts/1/bookmark", `{"collection": ""}`, http.StatusBadRequest},
+		{"refuses a long collection", http.MethodPut, "/v1/posts/1/bookmark", `{"collection": "` + strings.Repeat("a", 101) + `"}`, http.StatusBadRequest},
+		{"refuses unknown fields", http.MethodPut, "/v1/posts/1/bookmark", `{"folder": "go"}`, http.StatusBadRequest},
+		{"bookmarks a missing post", http.MethodPut, "/v1/posts/3/bookmark", "", http.StatusNotFound},
+		{"refuses an invalid cursor", http.MethodGet, "/v1/users/bookmarks?cursor=invalid", "", http.StatusBadRequest},
+		{"refuses a zero limit", http.MethodGet, "/v1/users/bookmarks?limit=0", "", http.StatusBadRequest},
+		{"refuses a large limit", http.MethodGet, "/v1/users/bookmarks?limit=51", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponseCode(t, tt.want, client.do(t, tt.method, tt.path, tt.body).Code)
+		})
+	}
+	if len(bookmarks.Bookmarks) != 0 {
+		t.Errorf("got bookmarks %+v, want none", bookmarks.Bookmarks)
+	}
+}
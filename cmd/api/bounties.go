@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/theluminousartemis/inkspire/internal/badges"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+type CreateBountyPayload struct {
+	Amount int `json:"amount" validate:"required,gte=50,lte=500"`
+}
+
+// CreateBounty godoc
+//
+//	@Summary		Offers a bounty on a question
+//	@Description	Stakes part of the user's reputation on a post, the bounty is awarded to the accepted or highest voted answer when it expires
+//	@Tags			posts, bounties
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int					true	"Post ID"
+//	@Param			payload	body		CreateBountyPayload	true	"Bounty payload"
+//	@Success		201		{object}	store.Bounty
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		409		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/bounty [post]
+func (app *application) createBountyHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+
+	var payload CreateBountyPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	bounty := &store.Bounty{
+		PostID:    post.ID,
+		UserID:    user.ID,
+		Amount:    payload.Amount,
+		ExpiresAt: time.Now().Add(app.config.bounty.duration),
+	}
+
+	ctx := r.Context()
+	if err := app.storage.Bounties.Create(ctx, bounty); err != nil {
+		switch err {
+		case store.ErrInsufficientReputation:
+			app.badRequestError(w, r, err)
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("post already has an open bounty"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.invalidateUser(ctx, user.ID)
+
+	if err := app.jsonResponse(w, http.StatusCreated, bounty); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// GetBounty godoc
+//
+//	@Summary		Fetches the bounty of a question
+//	@Description	Fetches the open bounty of a post
+//	@Tags			posts, bounties
+//	@Produce		json
+//	@Param			postID	path		int	true	"Post ID"
+//	@Success		200		{object}	store.Bounty
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/bounty [get]
+func (app *application) getBountyHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	bounty, err := app.storage.Bounties.GetOpenByPostID(r.Context(), post.ID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.bountyNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, bounty); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// processExpiredBounties awards or expires the bounties past their expiry.
+func (app *application) processExpiredBounties(ctx context.Context) error {
+	closed, err := app.storage.Bounties.ProcessExpired(ctx)
+	for _, bounty := range closed {
+		app.l.Infow("bounty closed", "bountyID", bounty.ID, "postID", bounty.PostID, "status", bounty.Status)
+		if bounty.AwardedUserID != nil {
+			app.invalidateUser(ctx, *bounty.AwardedUserID)
+			app.badges.Emit(badges.EventBountyAwarded, *bounty.AwardedUserID)
+		}
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+var errPostClosed = errors.New("question is closed and no longer accepts answers")
+
+type ClosePostPayload struct {
+	Reason      string `json:"reason" validate:"required,oneof=duplicate off_topic needs_details"`
+	DuplicateOf *int64 `json:"duplicate_of" validate:"required_if=Reason duplicate"`
+}
+
+// ClosePost godoc
+//
+//	@Summary		Closes a question
+//	@Description	Closes a post as a duplicate, off-topic or needing details, moderator only
+//	@Tags			posts, moderation
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int					true	"Post ID"
+//	@Param			payload	body		ClosePostPayload	true	"Close payload"
+//	@Success		204		{string}	string				"Post closed"
+//	@Failure		400		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		409		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/close [put]
+func (app *application) closePostHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+
+	closure, ok := app.readClosure(w, r, post, user)
+	if !ok {
+		return
+	}
+
+	if err := app.storage.Posts.Close(r.Context(), post.ID, closure); err != nil {
+		switch err {
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("post is already closed"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.l.Infow("Moderator/Admin has closed the post", "userID", user.ID, "postID", post.ID, "reason", closure.Reason)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReopenPost godoc
+//
+//	@Summary		Reopens a question
+//	@Description	Reopens a closed post and clears its close votes, moderator only
+//	@Tags			posts, moderation
+//	@Produce		json
+//	@Param			postID	path		int		true	"Post ID"
+//	@Success		204		{string}	string	"Post reopened"
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		409		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/reopen [put]
+func (app *application) reopenPostHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+
+	if err := app.storage.Posts.Reopen(r.Context(), post.ID); err != nil {
+		switch err {
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("post is not closed"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.l.Infow("Moderator/Admin has reopened the post", "userID", user.ID, "postID", post.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VoteToClosePost godoc
+//
+//	@Summary		Votes to close a question
+//	@Description	Casts or changes the user's close vote, the post is closed once enough users voted
+//	@Tags			posts, moderation
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int					true	"Post ID"
+//	@Param			payload	body		ClosePostPayload	true	"Close payload"
+//	@Success		200		{object}	store.CloseVoteResult
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		409		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/close-vote [put]
+func (app *application) closeVoteHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+
+	closure, ok := app.readClosure(w, r, post, user)
+	if !ok {
+		return
+	}
+
+	result, err := app.storage.Posts.VoteToClose(r.Context(), post.ID, closure, app.config.moderation.closeVotes)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.postNotFoundErrorResponse(w, r, err)
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("post is already closed"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, result); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// readClosure reads and validates a close payload for the post, it writes the
+// error response and reports false when the payload is invalid.
+func (app *application) readClosure(w http.ResponseWriter, r *http.Request, post *store.Post, user *store.User) (store.PostClosure, bool) {
+	var payload ClosePostPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return store.PostClosure{}, false
+	}
+
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return store.PostClosure{}, false
+	}
+
+	closure := store.PostClosure{
+		Reason:   payload.Reason,
+		ClosedBy: user.ID,
+	}
+
+	if payload.Reason != store.CloseReasonDuplicate {
+		return closure, true
+	}
+
+	if *payload.DuplicateOf == post.ID {
+		app.badRequestError(w, r, errors.New("a post cannot be a duplicate of itself"))
+		return store.PostClosure{}, false
+	}
+
+	if _, err := app.storage.Posts.GetByID(r.Context(), *payload.DuplicateOf); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.badRequestError(w, r, errors.New("duplicate post does not exist"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return store.PostClosure{}, false
+	}
+
+	closure.DuplicateOf = payload.DuplicateOf
+	return closure, true
+}
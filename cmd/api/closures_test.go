@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+	"github.com/theluminousartemis/inkspire/internal/store/cache"
+)
+
+func TestCloseVotes(t *testing.T) {
+	app := newTestApplication(t, config{moderation: moderationConfig{closeVotes: 3}})
+	client := newTestClient(t, app)
+	posts := app.storage.Posts.(*store.MockPostStore)
+	duplicateOf := int64(1)
+	posts.CloseVotes = map[int64]map[int64]store.PostClosure{
+		2: {3: {Reason: store.CloseReasonDuplicate, DuplicateOf: &duplicateOf, ClosedBy: 3}},
+	}
+
+	steps := []struct {
+		name string
+		body string
+		want store.CloseVoteResult
+	}{
+		{"counts the vote", `{"reason": "off_topic"}`, store.CloseVoteResult{Votes: 2}},
+		{"replaces the vote of the user", `{"reason": "duplicate", "duplicate_of": 1}`, store.CloseVoteResult{Votes: 2}},
+	}
+	for _, step := range steps {
+		var result store.CloseVoteResult
+		readData(t, client.do(t, http.MethodPut, "/v1/posts/2/close-vote", step.body), http.StatusOK, &result)
+		if result != step.want {
+			t.Errorf("%s: got %+v, want %+v", step.name, result, step.want)
+		}
+	}
+	if got := posts.CloseVotes[2][1]; got.Reason != store.CloseReasonDuplicate || got.ClosedBy != 1 {
+		t.Errorf("got close vote %+v for user 1, want a duplicate vote", got)
+	}
+
+	posts.CloseVotes[2][4] = store.PostClosure{Reason: store.CloseReasonOffTopic, ClosedBy: 4}
+	var result store.CloseVoteResult
+	readData(t, client.do(t, http.MethodPut, "/v1/posts/2/close-vote", `{"reason": "duplicate", "duplicate_of": 1}`), http.StatusOK, &result)
+	if result != (store.CloseVoteResult{Votes: 3, Closed: true}) {
+		t.Errorf("got %+v, want the third vote to close the post", result)
+	}
+
+	var post store.Post
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/2", ""), http.StatusOK, &post)
+	if post.Banner == nil || post.Banner.Reason != store.CloseReasonDuplicate || post.Banner.DuplicateURL != "/v1/posts/1" {
+		t.Errorf("got banner %+v, want the duplicate of post 1 picked by most voters", post.Banner)
+	}
+
+	checkResponseCode(t, http.StatusConflict, client.do(t, http.MethodPut, "/v1/posts/2/close-vote", `{"reason": "off_topic"}`).Code)
+}
+
+func TestCloseVoteErrors(t *testing.T) {
+	app := newTestApplication(t, config{moderation: moderationConfig{closeVotes: 3}})
+	client := newTestClient(t, app)
+	posts := app.storage.Posts.(*store.MockPostStore)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"refuses an unknown reason", `{"reason": "spam"}`},
+		{"needs the duplicated post", `{"reason": "duplicate"}`},
+		{"refuses a duplicate of itself", `{"reason": "duplicate", "duplicate_of": 2}`},
+		{"refuses a missing duplicated post", `{"reason": "duplicate", "duplicate_of": 3}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponseCode(t, http.StatusBadRequest, client.do(t, http.MethodPut, "/v1/posts/2/close-vote", tt.body).Code)
+		})
+	}
+	if len(posts.CloseVotes) != 0 {
+		t.Errorf("got close votes %v, want none", posts.CloseVotes)
+	}
+}
+
+func TestClosePost(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	posts := app.storage.Posts.(*store.MockPostStore)
+
+	checkResponseCode(t, http.StatusForbidden, client.do(t, http.MethodPut, "/v1/posts/2/close", `{"reason": "off_topic"}`).Code)
+	if len(posts.Closures) != 0 {
+		t.Fatalf("got closures %v, want none from a user", posts.Closures)
+	}
+
+	app.cache.Users.(*cache.MockUserStore).Role = store.Role{ID: 2, Name: "moderator", Level: 2}
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodPut, "/v1/posts/2/close", `{"reason": "off_topic"}`).Code)
+	var post store.Post
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/2", ""), http.StatusOK, &post)
+	if post.Banner == nil || post.Banner.Message != "This question has been closed as off-topic." {
+		t.Errorf("got banner %+v, want the off-topic banner", post.Banner)
+	}
+	checkResponseCode(t, http.StatusConflict, client.do(t, http.MethodPut, "/v1/posts/2/close", `{"reason": "off_topic"}`).Code)
+
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodPut, "/v1/posts/2/reopen", "").Code)
+	post = store.Post{}
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/2", ""), http.StatusOK, &post)
+	if post.Banner != nil {
+		t.Errorf("got banner %+v, want none after reopening", post.Banner)
+	}
+	checkResponseCode(t, http.StatusConflict, client.do(t, http.MethodPut, "/v1/posts/2/reopen", "").Code)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+var errCommentsLocked = errors.New("comments on this post are locked")
+
+type CommentSettingsPayload struct {
+	Locked        bool `json:"locked"`
+	MaxDepth      *int `json:"max_depth" validate:"omitempty,gte=1"`
+	FollowersOnly bool `json:"followers_only"`
+}
+
+// UpdateCommentSettings godoc
+//
+//	@Summary		Updates the comment settings of a post
+//	@Description	Locks the comments of a post against new comments and edits, limits how deep replies nest or only lets the followers of the author comment. A null max_depth uses the site-wide limit. Moderators can still comment on and edit the comments of locked posts
+//	@Tags			posts, comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Post ID"
+//	@Param			payload	body		CommentSettingsPayload	true	"Comment settings"
+//	@Success		200		{object}	store.CommentSettings
+//	@Failure		400		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{id}/comment-settings [put]
+func (app *application) updateCommentSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	var payload CommentSettingsPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if limit := app.config.comments.maxDepth; payload.MaxDepth != nil && *payload.MaxDepth > limit {
+		app.badRequestError(w, r, fmt.Errorf("max_depth cannot exceed the site-wide limit of %d", limit))
+		return
+	}
+
+	settings := store.CommentSettings(payload)
+	if err := app.storage.Posts.UpdateCommentSettings(r.Context(), post.ID, settings); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.postNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, settings); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// maxCommentDepth is the deepest replies can nest on the post, its own limit
+// or the site-wide one.
+func (app *application) maxCommentDepth(post *store.Post) int {
+	if post.CommentSettings.MaxDepth != nil {
+		return *post.CommentSettings.MaxDepth
+	}
+	return app.config.comments.maxDepth
+}
+
+// checkCommentsUnlocked refuses edits by the user to the comments of a locked
+// post unless they are a moderator. It writes the error response and returns
+// false when the edit is refused.
+func (app *application) checkCommentsUnlocked(w http.ResponseWriter, r *http.Request, post *store.Post, user *store.User) bool {
+	if !post.CommentSettings.Locked {
+		return true
+	}
+
+	moderator, err := app.checkRolePrecedence(r.Context(), user, "moderator")
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	if !moderator {
+		app.forbiddenErrorResponse(w, r, errCommentsLocked)
+		return false
+	}
+	return true
+}
+
+// checkCommentSettings enforces the comment settings of the post on a new
+// comment by the user at the depth, 1 for a top-level comment. It writes the
+// error response and returns false when the comment is refused.
+func (app *application) checkCommentSettings(w http.ResponseWriter, r *http.Request, post *store.Post, user *store.User, depth int) bool {
+	settings := post.CommentSettings
+
+	maxDepth := app.maxCommentDepth(post)
+	if depth > maxDepth {
+		app.forbiddenErrorResponse(w, r, fmt.Errorf("replies on this post cannot be nested deeper than %d levels", maxDepth))
+		return false
+	}
+
+	if !settings.Locked && (!settings.FollowersOnly || user.ID == post.UserID) {
+		return true
+	}
+
+	ctx := r.Context()
+	moderator, err := app.checkRolePrecedence(ctx, user, "moderator")
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	if moderator {
+		return true
+	}
+
+	if settings.Locked {
+		app.forbiddenErrorResponse(w, r, errCommentsLocked)
+		return false
+	}
+
+	following, err := app.storage.Followers.IsFollowing(ctx, user.ID, post.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	if !following {
+		app.forbiddenErrorResponse(w, r, fmt.Errorf("only the followers of %s can comment on this post", post.User.Username))
+		return false
+	}
+	return true
+}
@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/theluminousartemis/inkspire/internal/badges"
+	"github.com/theluminousartemis/inkspire/internal/notifications"
 	"github.com/theluminousartemis/inkspire/internal/store"
 )
 
@@ -30,6 +34,7 @@ type CommentPayload struct {
 //	@Param			payload	body		CommentPayload					true	"Comment payload"
 //	@Success		200		{object}	store.SwaggerCommentResponse	"Comment created"
 //	@Failure		400		{object}	error
+//	@Failure		403		{object}	error
 //	@Failure		500		{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id}/comments [post]
@@ -50,10 +55,19 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 
 	ctx := r.Context()
 
-	if *payload.ParentID == 0 {
+	if payload.ParentID != nil && *payload.ParentID == 0 {
 		payload.ParentID = nil
 	}
 
+	if payload.ParentID == nil && post.Closed() {
+		app.forbiddenErrorResponse(w, r, errPostClosed)
+		return
+	}
+
+	depth := 1
+	// the post author is told about top-level comments, the parent author
+	// about replies
+	recipientID, notificationType := post.UserID, notifications.TypeComment
 	if payload.ParentID != nil {
 		parentComment, err := app.storage.Comments.GetByID(ctx, *payload.ParentID)
 		if err != nil {
@@ -71,9 +85,19 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 			return
 		}
 
+		parentDepth, err := app.storage.Comments.GetDepth(ctx, parentComment.ID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		depth = parentDepth + 1
+		recipientID, notificationType = parentComment.UserID, notifications.TypeReply
 	}
 
 	user := getUserFromCtx(r)
+	if !app.checkCommentSettings(w, r, post, user, depth) {
+		return
+	}
 	comment := &store.Comment{
 		Content:  payload.Content,
 		PostID:   post.ID,
@@ -85,6 +109,11 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.badges.Emit(badges.EventCommentCreated, user.ID)
+	app.indexPosts(ctx, post.ID)
+	mentions := app.recordMentions(ctx, store.MentionSource{AuthorID: user.ID, PostID: post.ID, CommentID: &comment.ID}, post.Title, comment.Content)
+	comment.ContentHTML = app.renderMentions(comment.Content, mentions)
+	app.notify(ctx, recipientID, notificationType, user.ID, &post.ID, &comment.ID)
 
 	if err := app.jsonResponse(w, http.StatusCreated, comment); err != nil {
 		app.internalServerError(w, r, err)
@@ -93,10 +122,159 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 
 }
 
+// ListComments godoc
+//
+//	@Summary		List the comments of a post
+//	@Description	Lists a page of the top-level comments of a post with their replies down to a depth, the accepted answer first. Comments whose replies are not all listed carry a more_replies cursor, passing it as cursor lists those replies instead of the top-level comments
+//	@Tags			posts, comments
+//	@Produce		json
+//	@Param			id		path		int		true	"Post ID"
+//	@Param			limit	query		int		false	"Comments per page, 1 to 50"
+//	@Param			replies	query		int		false	"Replies listed per comment, 0 to 20"
+//	@Param			depth	query		int		false	"Levels of the tree listed, 1 to 10"
+//	@Param			sort	query		string	false	"Sort (oldest, newest or top)"
+//	@Param			cursor	query		string	false	"Cursor of the next page or of more replies"
+//	@Success		200		{object}	[]store.SwaggerCommentResponse
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{id}/comments [get]
+func (app *application) listCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	cq, ok := app.readCommentQuery(w, r)
+	if !ok {
+		return
+	}
+
+	comments, next, err := app.storage.Comments.GetPage(r.Context(), post.ID, post.AcceptedCommentID, cq)
+	if err != nil {
+		switch err {
+		case store.ErrInvalidCursor:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.renderPostMentions(r.Context(), post.ID, nil, comments); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, comments, next); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// CommentThread is a comment with its replies, preceded by its ancestors from
+// the top-level comment down to its parent.
+type CommentThread struct {
+	Ancestors []*store.Comment `json:"ancestors"`
+	Comment   *store.Comment   `json:"comment"`
+}
+
+// GetComment godoc
+//
+//	@Summary		Fetch a comment thread
+//	@Description	Fetches a comment with its ancestors, from the top-level comment down to its parent, and its replies down to a depth. Comments whose replies are not all listed carry a more_replies cursor for the comments endpoint
+//	@Tags			posts, comments
+//	@Produce		json
+//	@Param			postID		path		int		true	"Post ID"
+//	@Param			commentID	path		int		true	"Comment ID"
+//	@Param			limit		query		int		false	"Direct replies listed, 1 to 50"
+//	@Param			replies		query		int		false	"Replies listed per reply, 0 to 20"
+//	@Param			depth		query		int		false	"Levels of replies listed, 1 to 10"
+//	@Param			sort		query		string	false	"Sort (oldest, newest or top)"
+//	@Success		200			{object}	CommentThread
+//	@Failure		400			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID} [get]
+func (app *application) getCommentHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	comment := getCommentfromCtx(r)
+	if comment.PostID != post.ID {
+		app.commentNotFoundErrorResponse(w, r, store.ErrNotFound)
+		return
+	}
+
+	cq, ok := app.readCommentQuery(w, r)
+	if !ok {
+		return
+	}
+	cq.Cursor = store.CommentCursor{ParentID: comment.ID}.Encode()
+
+	ctx := r.Context()
+	path, err := app.storage.Comments.GetPath(ctx, comment.ID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.commentNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	replies, more, err := app.storage.Comments.GetPage(ctx, post.ID, post.AcceptedCommentID, cq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	for _, c := range path {
+		c.Accepted = post.AcceptedCommentID != nil && c.ID == *post.AcceptedCommentID
+	}
+	thread := CommentThread{
+		Ancestors: path[:len(path)-1],
+		Comment:   path[len(path)-1],
+	}
+	thread.Comment.Replies = replies
+	thread.Comment.MoreReplies = more
+
+	if err := app.renderPostMentions(ctx, post.ID, nil, path); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, thread); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// defaultCommentQuery pages a comment tree when the request does not.
+var defaultCommentQuery = store.CommentQuery{
+	Limit:   20,
+	Replies: 3,
+	Depth:   3,
+	Sort:    "oldest",
+}
+
+// readCommentQuery parses and validates the paging of a comment tree.
+func (app *application) readCommentQuery(w http.ResponseWriter, r *http.Request) (store.CommentQuery, bool) {
+	cq, err := defaultCommentQuery.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return cq, false
+	}
+
+	if err := validate.Struct(cq); err != nil {
+		app.badRequestError(w, r, err)
+		return cq, false
+	}
+	return cq, true
+}
+
 // DeleteComment godoc
 //
 //	@Summary		Delete a comment
-//	@Description	Delete a comment by its ID if the user is the owner or has appropriate role
+//	@Description	Delete a comment by its ID if the user is the owner or has appropriate role. Deleting the accepted answer unaccepts it
 //	@Tags			posts, comments
 //	@Accept			json
 //	@Produce		json
@@ -111,13 +289,134 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Request) {
 	comment := getCommentfromCtx(r)
 	ctx := r.Context()
-	err := app.storage.Comments.Delete(ctx, comment.ID)
+	events, err := app.storage.Comments.Delete(ctx, comment.ID)
 	if err != nil {
 		app.internalServerError(w, r, err)
+		return
 	}
+	app.invalidateReputation(ctx, events)
+	app.indexPosts(ctx, comment.PostID)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+type UpdateCommentPayload struct {
+	Content string `json:"content" validate:"required"`
+}
+
+var errCommentEditWindow = errors.New("the comment can no longer be edited")
+
+// UpdateComment godoc
+//
+//	@Summary		Edit a comment
+//	@Description	Edits a comment if the user is the owner or a moderator, within the edit window of their role. Only moderators can edit the comments of a post whose comments are locked. The replaced content is kept in the history of the comment
+//	@Tags			posts, comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID		path		int								true	"Post ID"
+//	@Param			commentID	path		int								true	"Comment ID"
+//	@Param			payload		body		UpdateCommentPayload			true	"Comment payload"
+//	@Success		200			{object}	store.SwaggerCommentResponse	"Comment edited"
+//	@Failure		400			{object}	error
+//	@Failure		403			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID} [patch]
+func (app *application) updateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	comment := getCommentfromCtx(r)
+	if comment.PostID != post.ID {
+		app.commentNotFoundErrorResponse(w, r, store.ErrNotFound)
+		return
+	}
+	user := getUserFromCtx(r)
+
+	var payload UpdateCommentPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if !app.withinEditWindow(user, comment) {
+		app.forbiddenErrorResponse(w, r, errCommentEditWindow)
+		return
+	}
+
+	if !app.checkCommentsUnlocked(w, r, post, user) {
+		return
+	}
+
+	ctx := r.Context()
+	comment.Content = payload.Content
+	if err := app.storage.Comments.Update(ctx, comment, user.ID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.commentNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.indexPosts(ctx, comment.PostID)
+	src := store.MentionSource{AuthorID: comment.UserID, PostID: comment.PostID, CommentID: &comment.ID}
+	mentions := app.recordMentions(ctx, src, post.Title, comment.Content)
+	comment.ContentHTML = app.renderMentions(comment.Content, mentions)
+
+	if err := app.jsonResponse(w, http.StatusOK, comment); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// ListCommentEdits godoc
+//
+//	@Summary		List the edits of a comment
+//	@Description	Lists the past versions of a comment replaced by edits, newest first. Only the owner of the comment and moderators can read its history
+//	@Tags			posts, comments
+//	@Produce		json
+//	@Param			postID		path		int	true	"Post ID"
+//	@Param			commentID	path		int	true	"Comment ID"
+//	@Success		200			{object}	[]store.CommentEdit
+//	@Failure		403			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID}/history [get]
+func (app *application) listCommentEditsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	comment := getCommentfromCtx(r)
+	if comment.PostID != post.ID || comment.Deleted {
+		app.commentNotFoundErrorResponse(w, r, store.ErrNotFound)
+		return
+	}
+
+	edits, err := app.storage.Comments.GetEdits(r.Context(), comment.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, edits); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// withinEditWindow reports whether the user may still edit the comment, the
+// window depends on their role and roles without one fall back to users'.
+func (app *application) withinEditWindow(user *store.User, comment *store.Comment) bool {
+	windows := app.config.comments.editWindows
+	window, ok := windows[user.Role.Name]
+	if !ok {
+		window = windows["user"]
+	}
+	return window == 0 || time.Since(comment.CreatedAt) <= window
+}
+
 func (app *application) commentsContextMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		idParam := chi.URLParam(r, "commentID")
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+	"github.com/theluminousartemis/inkspire/internal/store/cache"
+)
+
+func TestUpdateComment(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	comments := app.storage.Comments.(*store.MockCommentStore)
+
+	var comment store.Comment
+	readData(t, client.do(t, http.MethodPatch, "/v1/posts/2/comments/3", `{"content": "Edited answer"}`), http.StatusOK, &comment)
+	if comment.Content != "Edited answer" || comment.EditedAt == nil {
+		t.Errorf("got %+v, want the edited content with its edit time", comment)
+	}
+	readData(t, client.do(t, http.MethodPatch, "/v1/posts/2/comments/3", `{"content": "Edited again"}`), http.StatusOK, nil)
+	if len(comments.Edits) != 2 || comments.Edits[1].CommentID != 3 {
+		t.Fatalf("got edits %+v, want two edits of comment 3", comments.Edits)
+	}
+
+	var edits []store.CommentEdit
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/2/comments/3/history", ""), http.StatusOK, &edits)
+	if len(edits) != 2 || edits[0].PreviousContent != "Edited answer" || edits[1].PreviousContent != "Other answer" || *edits[0].EditorID != 1 {
+		t.Errorf("got %+v, want both previous versions newest first", edits)
+	}
+
+	var thread CommentThread
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/2/comments/3", ""), http.StatusOK, &thread)
+	if thread.Comment == nil || thread.Comment.Content != "Edited again" {
+		t.Errorf("got %+v, want the last edit", thread.Comment)
+	}
+}
+
+func TestUpdateCommentErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		windows map[string]time.Duration
+		method  string
+		path    string
+		body    string
+		want    int
+	}{
+		{"edits a comment through another post", nil, http.MethodPatch, "/v1/posts/1/comments/3", `{"content": "Moved"}`, http.StatusNotFound},
+		{"edits the comment of another user", nil, http.MethodPatch, "/v1/posts/1/comments/1", `{"content": "Not mine"}`, http.StatusForbidden},
+		{"edits a missing comment", nil, http.MethodPatch, "/v1/posts/2/comments/9", `{"content": "Missing"}`, http.StatusNotFound},
+		{"edits with empty content", nil, http.MethodPatch, "/v1/posts/2/comments/3", `{"content": ""}`, http.StatusBadRequest},
+		{"edits after the edit window", map[string]time.Duration{"user": time.Nanosecond}, http.MethodPatch, "/v1/posts/2/comments/3", `{"content": "Late"}`, http.StatusForbidden},
+		{"reads the history through another post", nil, http.MethodGet, "/v1/posts/1/comments/3/history", "", http.StatusNotFound},
+		{"reads the history of another user", nil, http.MethodGet, "/v1/posts/1/comments/1/history", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t, config{comments: commentsConfig{editWindows: tt.windows}})
+			client := newTestClient(t, app)
+			checkResponseCode(t, tt.want, client.do(t, tt.method, tt.path, tt.body).Code)
+			if edits := app.storage.Comments.(*store.MockCommentStore).Edits; len(edits) != 0 {
+				t.Errorf("got edits %+v, want none", edits)
+			}
+		})
+	}
+}
+
+func TestModerateComment(t *testing.T) {
+	windows := map[string]time.Duration{"user": time.Nanosecond, "moderator": 0}
+	app := newTestApplication(t, config{comments: commentsConfig{editWindows: windows}})
+	client := newTestClient(t, app)
+	app.cache.Users.(*cache.MockUserStore).Role = store.Role{ID: 2, Name: "moderator", Level: 2}
+
+	readData(t, client.do(t, http.MethodPatch, "/v1/posts/1/comments/1", `{"content": "Moderated"}`), http.StatusOK, nil)
+
+	var edits []store.CommentEdit
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/1/comments/1/history", ""), http.StatusOK, &edits)
+	if len(edits) != 1 || edits[0].PreviousContent != "Answer" || *edits[0].EditorID != 1 {
+		t.Errorf("got %+v, want the edit of the moderator", edits)
+	}
+	checkResponseCode(t, http.StatusNotFound, client.do(t, http.MethodGet, "/v1/posts/2/comments/1/history", "").Code)
+}
+
+func commentIDs(comments []*store.Comment) []int64 {
+	ids := make([]int64, len(comments))
+	for i, c := range comments {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func TestListComments(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	comments := app.storage.Comments.(*store.MockCommentStore)
+
+	list := func(t *testing.T, query string) ([]*store.Comment, string) {
+		t.Helper()
+		var page []*store.Comment
+		next := readData(t, client.do(t, http.MethodGet, "/v1/posts/1/comments?"+query, ""), http.StatusOK, &page)
+		return page, next
+	}
+
+	page, next := list(t, "limit=1")
+	if !slices.Equal(commentIDs(page), []int64{1}) || !slices.Equal(commentIDs(page[0].Replies), []int64{2}) || next == "" {
+		t.Fatalf("got %v with cursor %q, want comment 1 and its reply with a cursor", commentIDs(page), next)
+	}
+	if want := (store.CommentQuery{Limit: 1, Replies: 3, Depth: 3, Sort: "oldest"}); comments.Query != want {
+		t.Errorf("got query %+v, want %+v", comments.Query, want)
+	}
+	page, next = list(t, "limit=1&cursor="+next)
+	if !slices.Equal(commentIDs(page), []int64{4}) || next != "" {
+		t.Errorf("got %v with cursor %q, want comment 4 on the last page", commentIDs(page), next)
+	}
+
+	page, _ = list(t, "sort=newest")
+	if !slices.Equal(commentIDs(page), []int64{4, 1}) {
+		t.Errorf("got %v, want the newest comment first", commentIDs(page))
+	}
+
+	page, _ = list(t, "replies=0")
+	if len(page) != 2 || len(page[0].Replies) != 0 || page[0].ReplyCount != 1 || page[0].MoreReplies == "" {
+		t.Fatalf("got %+v, want comment 1 without its reply but with a cursor to it", *page[0])
+	}
+	page, next = list(t, "cursor="+page[0].MoreReplies)
+	if !slices.Equal(commentIDs(page), []int64{2}) || next != "" {
+		t.Errorf("got %v with cursor %q, want the reply to comment 1", commentIDs(page), next)
+	}
+
+	app.storage.Posts.(*store.MockPostStore).Accepted = map[int64]int64{1: 4}
+	page, _ = list(t, "")
+	if !slices.Equal(commentIDs(page), []int64{4, 1}) || !page[0].Accepted {
+		t.Errorf("got %v, want the accepted answer first", commentIDs(page))
+	}
+}
+
+func TestListCommentsErrors(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"invalid cursor", "cursor=invalid"},
+		{"negative cursor", "cursor=" + base64.RawURLEncoding.EncodeToString([]byte("-1,0"))},
+		{"zero limit", "limit=0"},
+		{"large limit", "limit=51"},
+		{"deep trees", "depth=11"},
+		{"unknown sort", "sort=random"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponseCode(t, http.StatusBadRequest, client.do(t, http.MethodGet, "/v1/posts/1/comments?"+tt.query, "").Code)
+		})
+	}
+}
+
+func TestGetPostComments(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	comments := app.storage.Comments.(*store.MockCommentStore)
+
+	var post store.Post
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/1", ""), http.StatusOK, &post)
+	if !slices.Equal(commentIDs(post.Comments), []int64{1, 4}) || post.CommentsCursor != "" || comments.Query != defaultCommentQuery {
+		t.Errorf("got %v with cursor %q and query %+v, want the first page of the default query", commentIDs(post.Comments), post.CommentsCursor, comments.Query)
+	}
+
+	limit := defaultCommentQuery.Limit
+	defaultCommentQuery.Limit = 1
+	t.Cleanup(func() { defaultCommentQuery.Limit = limit })
+
+	post = store.Post{}
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/1", ""), http.StatusOK, &post)
+	if !slices.Equal(commentIDs(post.Comments), []int64{1}) || post.CommentsCursor == "" {
+		t.Fatalf("got %v with cursor %q, want comment 1 with a cursor", commentIDs(post.Comments), post.CommentsCursor)
+	}
+	var page []*store.Comment
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/1/comments?cursor="+post.CommentsCursor, ""), http.StatusOK, &page)
+	if !slices.Equal(commentIDs(page), []int64{4}) {
+		t.Errorf("got %v, want the comments cursor of the post to resume after comment 1", commentIDs(page))
+	}
+}
@@ -34,6 +34,31 @@ func (app *application) commentNotFoundErrorResponse(w http.ResponseWriter, r *h
 	writeJSONError(w, http.StatusNotFound, "Comment not found error")
 }
 
+func (app *application) badgeNotFoundErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.l.Warnf("badge not found error: %v path: %s err: %v", r.Method, r.URL.Path, err.Error())
+	writeJSONError(w, http.StatusNotFound, "Badge not found error")
+}
+
+func (app *application) bountyNotFoundErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.l.Warnf("bounty not found error: %v path: %s err: %v", r.Method, r.URL.Path, err.Error())
+	writeJSONError(w, http.StatusNotFound, "Bounty not found error")
+}
+
+func (app *application) bookmarkNotFoundErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.l.Warnf("bookmark not found error: %v path: %s err: %v", r.Method, r.URL.Path, err.Error())
+	writeJSONError(w, http.StatusNotFound, "Bookmark not found error")
+}
+
+func (app *application) tagNotFoundErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.l.Warnf("tag not found error: %v path: %s err: %v", r.Method, r.URL.Path, err.Error())
+	writeJSONError(w, http.StatusNotFound, "Tag not found error")
+}
+
+func (app *application) notificationNotFoundErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.l.Warnf("notification not found error: %v path: %s err: %v", r.Method, r.URL.Path, err.Error())
+	writeJSONError(w, http.StatusNotFound, "Notification not found error")
+}
+
 func (app *application) unauthorizedBasicResponse(w http.ResponseWriter, r *http.Request, err error) {
 	app.l.Warnf("unauthorized basic error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
 	w.Header().Set("WWW-Authenticate", `Basic realm="restricted", charset=UTF-8"`)
@@ -50,6 +75,11 @@ func (app *application) forbiddenResponse(w http.ResponseWriter, r *http.Request
 	writeJSONError(w, http.StatusForbidden, "forbidden")
 }
 
+func (app *application) forbiddenErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.l.Warnw("forbidden response error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	writeJSONError(w, http.StatusForbidden, err.Error())
+}
+
 func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http.Request, retryAfter string) {
 	app.l.Infow("rate limit exceeded", "remote_addr", r.RemoteAddr, "method", r.Method, "path", r.URL.Path)
 	w.Header().Set("Retry-After", retryAfter)
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+// Explore godoc
+//
+//	@Summary		Fetches the explore feed
+//	@Description	Fetches the posts of everyone, newest first, top by votes and comments over a window or trending. Works without authentication
+//	@Tags			feed
+//	@Produce		json
+//	@Param			sort	query		string	false	"Sort (latest, top or trending)"
+//	@Param			window	query		string	false	"Window of the top sort (day, week, month, year or all)"
+//	@Param			tags	query		string	false	"Tags"
+//	@Param			limit	query		int		false	"Limit"
+//	@Param			offset	query		int		false	"Offset"
+//	@Success		200		{object}	[]store.PostWithMetadata
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/explore [get]
+func (app *application) exploreHandler(w http.ResponseWriter, r *http.Request) {
+	eq := store.ExploreQuery{
+		Limit:  10,
+		Offset: 0,
+		Sort:   "latest",
+		Window: "week",
+		Tags:   []string{},
+	}
+
+	eq, err := eq.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(eq); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	if eq.Tags, err = app.storage.Tags.Canonical(ctx, eq.Tags); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	var trending []int64
+	if eq.Sort == "trending" {
+		if trending, err = app.trendingPosts(ctx); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	var viewerID int64
+	if user := getUserFromCtx(r); user != nil {
+		viewerID = user.ID
+	}
+
+	posts, err := app.storage.Posts.GetExploreFeed(ctx, viewerID, eq, trending)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, posts); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// trendingPosts returns the ids of the trending posts cached by the trending
+// job, computing them when the cache is empty.
+func (app *application) trendingPosts(ctx context.Context) ([]int64, error) {
+	ids, ok, err := app.cache.Trending.Get(ctx)
+	if err != nil || ok {
+		return ids, err
+	}
+
+	scores, err := app.computeTrending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids = make([]int64, 0, len(scores))
+	for _, s := range scores {
+		ids = append(ids, s.PostID)
+	}
+	return ids, nil
+}
+
+// refreshTrending recomputes the trending posts and caches them, it runs as a
+// background job.
+func (app *application) refreshTrending(ctx context.Context) error {
+	_, err := app.computeTrending(ctx)
+	return err
+}
+
+func (app *application) computeTrending(ctx context.Context) ([]store.PostScore, error) {
+	cfg := app.config.explore
+	since := time.Now().Add(-cfg.trendingWindow)
+	scores, err := app.storage.Posts.GetTrendingScores(ctx, since, cfg.trendingGravity, cfg.trendingSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := app.cache.Trending.Set(ctx, scores); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
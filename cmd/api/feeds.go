@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/theluminousartemis/inkspire/internal/store"
@@ -9,23 +10,66 @@ import (
 // getUserFeedHandler godoc
 //
 //	@Summary		Fetches the user feed
-//	@Description	Fetches the user feed
+//	@Description	Fetches the posts of the user, of the users they follow and of the tags they follow, leaving out the tags they ignore. The ranked sort scores the newest posts by recency, affinity with the author, votes and comments
 //	@Tags			feed
 //	@Accept			json
 //	@Produce		json
-//	@Param			since	query		string	false	"Since"
-//	@Param			until	query		string	false	"Until"
-//	@Param			limit	query		int		false	"Limit"
-//	@Param			offset	query		int		false	"Offset"
-//	@Param			sort	query		string	false	"Sort"
-//	@Param			tags	query		string	false	"Tags"
-//	@Param			search	query		string	false	"Search"
-//	@Success		200		{object}	[]store.PostWithMetadata
-//	@Failure		400		{object}	error
-//	@Failure		500		{object}	error
+//	@Param			limit		query		int		false	"Limit"
+//	@Param			offset		query		int		false	"Offset"
+//	@Param			cursor		query		string	false	"Cursor of the next page, replaces offset for the asc and desc sorts"
+//	@Param			sort		query		string	false	"Sort (asc, desc, top, updated, comments or ranked)"
+//	@Param			since		query		string	false	"Only posts created at or after, RFC 3339 or YYYY-MM-DD HH:MM:SS"
+//	@Param			until		query		string	false	"Only posts created before, RFC 3339 or YYYY-MM-DD HH:MM:SS"
+//	@Param			tags		query		string	false	"Tags"
+//	@Param			search		query		string	false	"Search"
+//	@Param			answered	query		bool	false	"Only answered (true) or unanswered (false) questions"
+//	@Param			bountied	query		bool	false	"Only questions with an open bounty"
+//	@Success		200			{object}	[]store.PostWithMetadata
+//	@Failure		400			{object}	error
+//	@Failure		500			{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/users/feed [get]
 func (app *application) getUserFeedHandler(w http.ResponseWriter, r *http.Request) {
+	fq, ok := app.readFeedQuery(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	userID := getUserFromCtx(r).ID
+
+	var feed []store.PostWithMetadata
+	var next string
+	var err error
+	if fq.Sort == "ranked" {
+		cfg := app.config.feed
+		feed, err = app.storage.Posts.GetRankedUserFeed(ctx, userID, fq, cfg.rankingWeights, cfg.rankedCandidates)
+	} else {
+		var ok bool
+		feed, next, ok = app.timelineFeed(ctx, userID, fq)
+		if !ok {
+			feed, next, err = app.storage.Posts.GetUserFeed(ctx, userID, fq)
+		}
+	}
+	if err != nil {
+		switch err {
+		case store.ErrInvalidCursor:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, feed, next); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// readFeedQuery parses and validates the feed filters, the tags filter is
+// mapped to canonical tags so synonyms match too.
+func (app *application) readFeedQuery(w http.ResponseWriter, r *http.Request) (store.PagintatedFeedQuery, bool) {
 	fq := store.PagintatedFeedQuery{
 		Limit:  10,
 		Offset: 0,
@@ -37,23 +81,22 @@ func (app *application) getUserFeedHandler(w http.ResponseWriter, r *http.Reques
 	fq, err := fq.Parse(r)
 	if err != nil {
 		app.badRequestError(w, r, err)
-		return
+		return fq, false
 	}
 
 	if err := validate.Struct(fq); err != nil {
 		app.badRequestError(w, r, err)
-		return
+		return fq, false
 	}
 
-	ctx := r.Context()
-	feed, err := app.storage.Posts.GetUserFeed(ctx, int64(4), fq)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
+	if fq.Cursor != "" && (fq.Offset > 0 || !fq.Keyset()) {
+		app.badRequestError(w, r, errors.New("cursor can only be used with the asc and desc sorts and without an offset"))
+		return fq, false
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, feed); err != nil {
+	if fq.Tags, err = app.storage.Tags.Canonical(r.Context(), fq.Tags); err != nil {
 		app.internalServerError(w, r, err)
-		return
+		return fq, false
 	}
+	return fq, true
 }
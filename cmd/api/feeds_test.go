@@ -0,0 +1,167 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+func feedIDs(feed []store.PostWithMetadata) []int64 {
+	ids := make([]int64, len(feed))
+	for i, p := range feed {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestUserFeedCursor(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+
+	tests := []struct {
+		name  string
+		query string
+		pages [][]int64
+	}{
+		{"newest first", "limit=2", [][]int64{{4, 3}, {2, 1}}},
+		{"oldest first", "limit=3&sort=asc", [][]int64{{1, 2, 3}, {4}}},
+		{"breaks creation time ties on the id", "limit=1&sort=asc", [][]int64{{1}, {2}, {3}, {4}}},
+		{"single page", "limit=4", [][]int64{{4, 3, 2, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var feed []store.PostWithMetadata
+			next := readData(t, client.do(t, http.MethodGet, "/v1/users/feed?"+tt.query, ""), http.StatusOK, &feed)
+			for i, want := range tt.pages {
+				if got := feedIDs(feed); !slices.Equal(got, want) {
+					t.Fatalf("page %d: got posts %v, want %v", i, got, want)
+				}
+				if last := i == len(tt.pages)-1; last != (next == "") {
+					t.Fatalf("page %d: got cursor %q, want one on every page but the last", i, next)
+				}
+				if next != "" {
+					next = readData(t, client.do(t, http.MethodGet, "/v1/users/feed?"+tt.query+"&cursor="+next, ""), http.StatusOK, &feed)
+				}
+			}
+		})
+	}
+}
+
+func TestUserFeedOffset(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+
+	var feed []store.PostWithMetadata
+	readData(t, client.do(t, http.MethodGet, "/v1/users/feed?limit=2&offset=1", ""), http.StatusOK, &feed)
+	if got := feedIDs(feed); !slices.Equal(got, []int64{3, 2}) {
+		t.Errorf("got posts %v, want posts 3 and 2", got)
+	}
+	if fq := app.storage.Posts.(*store.MockPostStore).FeedQuery; fq.Offset != 1 || fq.Cursor != "" {
+		t.Errorf("got query %+v, want the offset kept", fq)
+	}
+}
+
+func TestUserFeedCursorErrors(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	cursor := store.Cursor{ID: 2}.Encode()
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"invalid cursor", "cursor=invalid"},
+		{"cursor with an offset", "offset=1&cursor=" + cursor},
+		{"cursor with the top sort", "sort=top&cursor=" + cursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponseCode(t, http.StatusBadRequest, client.do(t, http.MethodGet, "/v1/users/feed?"+tt.query, "").Code)
+		})
+	}
+}
+
+func TestUserFeedTimeWindow(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	posts := app.storage.Posts.(*store.MockPostStore)
+
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []int64
+		since time.Time
+		until time.Time
+	}{
+		{
+			name:  "since is inclusive",
+			query: url.Values{"since": {"2026-01-01T01:00:00Z"}},
+			want:  []int64{4, 3, 2},
+			since: time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "until is exclusive",
+			query: url.Values{"until": {"2026-01-01 01:00:00"}},
+			want:  []int64{1},
+			until: time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "zone offsets are applied",
+			query: url.Values{"since": {"2026-01-01T02:00:00+01:00"}, "until": {"2026-01-01T02:00:00Z"}},
+			want:  []int64{3, 2},
+			since: time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC),
+			until: time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var feed []store.PostWithMetadata
+			readData(t, client.do(t, http.MethodGet, "/v1/users/feed?"+tt.query.Encode(), ""), http.StatusOK, &feed)
+			if got := feedIDs(feed); !slices.Equal(got, tt.want) {
+				t.Errorf("got posts %v, want %v", got, tt.want)
+			}
+			fq := posts.FeedQuery
+			if (fq.Since == nil) != tt.since.IsZero() || fq.Since != nil && !fq.Since.Equal(tt.since) {
+				t.Errorf("got since %v, want %v", fq.Since, tt.since)
+			}
+			if (fq.Until == nil) != tt.until.IsZero() || fq.Until != nil && !fq.Until.Equal(tt.until) {
+				t.Errorf("got until %v, want %v", fq.Until, tt.until)
+			}
+		})
+	}
+}
+
+func TestUserFeedSorts(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	posts := app.storage.Posts.(*store.MockPostStore)
+
+	for _, sort := range []string{"updated", "comments"} {
+		t.Run(sort, func(t *testing.T) {
+			next := readData(t, client.do(t, http.MethodGet, "/v1/users/feed?limit=1&sort="+sort, ""), http.StatusOK, nil)
+			if posts.FeedQuery.Sort != sort || next != "" {
+				t.Errorf("got sort %q and cursor %q, want %q without a cursor", posts.FeedQuery.Sort, next, sort)
+			}
+		})
+	}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown sort", "sort=random"},
+		{"malformed since", "since=2026-01-01"},
+		{"malformed until", "until=yesterday"},
+		{"empty window", "since=2026-01-02T00:00:00Z&until=2026-01-02T00:00:00Z"},
+		{"reversed window", "since=2026-01-02T00:00:00Z&until=2026-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponseCode(t, http.StatusBadRequest, client.do(t, http.MethodGet, "/v1/users/feed?"+tt.query, "").Code)
+		})
+	}
+}
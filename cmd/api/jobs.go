@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// runPeriodic runs fn every interval until ctx is cancelled, errors are logged
+// and retried on the next tick.
+func (app *application) runPeriodic(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	app.l.Infow("starting job", "job", name, "interval", interval.String())
+	for {
+		select {
+		case <-ctx.Done():
+			app.l.Infow("job has stopped", "job", name)
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				app.l.Errorw("job failed", "job", name, "error", err)
+			}
+		}
+	}
+}
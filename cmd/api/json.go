@@ -42,3 +42,14 @@ func (app *application) jsonResponse(w http.ResponseWriter, status int, data any
 
 	return writeJSON(w, status, &envelope{Data: data})
 }
+
+// paginatedJSONResponse adds the cursor of the next page to the envelope, it is
+// left out on the last page.
+func (app *application) paginatedJSONResponse(w http.ResponseWriter, status int, data any, nextCursor string) error {
+	type envelope struct {
+		Data       any    `json:"data"`
+		NextCursor string `json:"next_cursor,omitempty"`
+	}
+
+	return writeJSON(w, status, &envelope{Data: data, NextCursor: nextCursor})
+}
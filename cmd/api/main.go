@@ -1,18 +1,23 @@
 package main
 
 import (
+	"context"
 	"expvar"
 	"log"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/theluminousartemis/inkspire/internal/auth"
+	"github.com/theluminousartemis/inkspire/internal/badges"
 	"github.com/theluminousartemis/inkspire/internal/db"
 	"github.com/theluminousartemis/inkspire/internal/env"
 	"github.com/theluminousartemis/inkspire/internal/mailer"
+	"github.com/theluminousartemis/inkspire/internal/ranking"
 	"github.com/theluminousartemis/inkspire/internal/ratelimiter"
+	"github.com/theluminousartemis/inkspire/internal/search"
 	"github.com/theluminousartemis/inkspire/internal/store"
 	"github.com/theluminousartemis/inkspire/internal/store/cache"
+	"github.com/theluminousartemis/inkspire/internal/timeline"
 	"go.uber.org/zap"
 )
 
@@ -70,6 +75,46 @@ func main() {
 			Timeframe:            time.Minute * 2,
 			Enabled:              env.GetBool("RATELIMITER_ENABLED", true),
 		},
+		reputation: reputationConfig{
+			downvote:  env.GetInt("REPUTATION_DOWNVOTE", 125),
+			editPosts: env.GetInt("REPUTATION_EDIT_POSTS", 2000),
+		},
+		bounty: bountyConfig{
+			duration:      env.GetDuration("BOUNTY_DURATION", time.Hour*24*7),
+			checkInterval: env.GetDuration("BOUNTY_CHECK_INTERVAL", time.Minute),
+		},
+		moderation: moderationConfig{
+			closeVotes: env.GetInt("MODERATION_CLOSE_VOTES", 5),
+		},
+		explore: exploreConfig{
+			trendingInterval: env.GetDuration("EXPLORE_TRENDING_INTERVAL", time.Minute*5),
+			trendingWindow:   env.GetDuration("EXPLORE_TRENDING_WINDOW", time.Hour*24*7),
+			trendingGravity:  env.GetFloat("EXPLORE_TRENDING_GRAVITY", 1.8),
+			trendingSize:     env.GetInt("EXPLORE_TRENDING_SIZE", 500),
+		},
+		comments: commentsConfig{
+			editWindows: map[string]time.Duration{
+				"user":      env.GetDuration("COMMENT_EDIT_WINDOW_USER", 15*time.Minute),
+				"moderator": env.GetDuration("COMMENT_EDIT_WINDOW_MODERATOR", 0),
+				"admin":     env.GetDuration("COMMENT_EDIT_WINDOW_ADMIN", 0),
+			},
+			maxDepth: env.GetInt("COMMENT_MAX_DEPTH", 8),
+		},
+		feed: feedConfig{
+			rankedCandidates: env.GetInt("FEED_RANKED_CANDIDATES", 200),
+			rankingWeights: ranking.Weights{
+				Recency:  env.GetFloat("FEED_WEIGHT_RECENCY", 3),
+				Affinity: env.GetFloat("FEED_WEIGHT_AFFINITY", 1),
+				Votes:    env.GetFloat("FEED_WEIGHT_VOTES", 0.5),
+				Comments: env.GetFloat("FEED_WEIGHT_COMMENTS", 0.5),
+				HalfLife: time.Duration(env.GetInt("FEED_RECENCY_HALF_LIFE_HOURS", 24)) * time.Hour,
+			},
+		},
+		search: searchConfig{
+			backend:   env.GetString("SEARCH_BACKEND", "postgres"),
+			indexPath: env.GetString("SEARCH_INDEX_PATH", "./data/search"),
+		},
+		reactions:   env.GetList("REACTIONS", []string{"thumbs_up", "thumbs_down", "heart", "laugh", "hooray", "confused", "rocket", "eyes"}),
 		frontendURL: env.GetString("FRONTEND_URL", "http://localhost:4000"),
 	}
 	//logger
@@ -105,6 +150,32 @@ func main() {
 		cache, cfg.ratelimiter.RequestsPerTimeFrame, cfg.ratelimiter.Timeframe,
 	)
 
+	//badges
+	badgeEngine := badges.NewEngine(store, logger, 2, 256)
+	badgeEngine.Start()
+	defer badgeEngine.Stop()
+
+	//search
+	var searcher search.Searcher
+	switch cfg.search.backend {
+	case "index":
+		index, err := search.NewIndex(cfg.search.indexPath, store.Search)
+		if err != nil {
+			log.Fatal(err)
+		}
+		searcher = index
+		logger.Infow("search index opened", "path", cfg.search.indexPath)
+	default:
+		searcher = search.NewPostgres(store)
+	}
+	defer searcher.Close()
+
+	// timelines live in Redis, the feed is read from the database without it
+	var timelines *timeline.Service
+	if cfg.redisCfg.enabled {
+		timelines = timeline.New(store, cache)
+	}
+
 	jwtAuthenticator := auth.NewJWTAuthenticator(cfg.auth.token.secret, cfg.auth.token.iss, cfg.auth.token.iss)
 	expvar.NewString("version").Set(version)
 	expvar.Publish("database", expvar.Func(func() any {
@@ -118,8 +189,17 @@ func main() {
 		authenticator: jwtAuthenticator,
 		cache:         cache,
 		rateLimiter:   ratelimiter,
+		badges:        badgeEngine,
+		search:        searcher,
+		timelines:     timelines,
 	}
 
+	//background jobs
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+	go app.runPeriodic(jobsCtx, "bounties", cfg.bounty.checkInterval, app.processExpiredBounties)
+	go app.runPeriodic(jobsCtx, "trending", cfg.explore.trendingInterval, app.refreshTrending)
+
 	mux := app.mount()
 	app.start(mux)
 }
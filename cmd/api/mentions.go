@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/theluminousartemis/inkspire/internal/mailer"
+	"github.com/theluminousartemis/inkspire/internal/notifications"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+// recordMentions stores the users mentioned in the content of a post or a
+// comment and notifies the ones mentioned by it for the first time, the author
+// excepted. Failures are only logged, the content then renders without links.
+func (app *application) recordMentions(ctx context.Context, src store.MentionSource, title, content string) []store.Mention {
+	mentions, added, err := app.storage.Mentions.Set(ctx, src, store.ParseMentions(content))
+	if err != nil {
+		app.l.Errorw("error recording mentions", "postID", src.PostID, "commentID", src.CommentID, "error", err)
+		return nil
+	}
+
+	var notify []store.Mention
+	for _, m := range added {
+		if m.UserID != src.AuthorID {
+			notify = append(notify, m)
+			app.notify(ctx, m.UserID, notifications.TypeMention, src.AuthorID, &src.PostID, src.CommentID)
+		}
+	}
+	if len(notify) > 0 {
+		go app.notifyMentioned(src, title, notify)
+	}
+	return mentions
+}
+
+// notifyMentioned emails the mentioned users. It runs outside of the request
+// so failures are only logged.
+func (app *application) notifyMentioned(src store.MentionSource, title string, mentions []store.Mention) {
+	author, err := app.storage.Users.GetByID(context.Background(), src.AuthorID)
+	if err != nil {
+		app.l.Errorw("error fetching mention author", "userID", src.AuthorID, "error", err)
+		return
+	}
+
+	isProdEnv := app.config.env == "production"
+	for _, m := range mentions {
+		vars := struct {
+			Username    string
+			MentionedBy string
+			InComment   bool
+			PostTitle   string
+			PostURL     string
+		}{
+			Username:    m.Username,
+			MentionedBy: author.Username,
+			InComment:   src.CommentID != nil,
+			PostTitle:   title,
+			PostURL:     fmt.Sprintf("%s/posts/%d", app.config.frontendURL, src.PostID),
+		}
+
+		status, err := app.mailer.Send(mailer.MentionTemplate, m.Username, m.Email, vars, isProdEnv)
+		if err != nil {
+			app.l.Errorw("error sending mention email", "userID", m.UserID, "error", err)
+			continue
+		}
+		app.l.Infow("Email sent", "status code", status)
+	}
+}
+
+// renderMentions escapes the content and links the mentions of the users in
+// mentions to their profiles, other mentions are left as text.
+func (app *application) renderMentions(content string, mentions []store.Mention) string {
+	users := make(map[string]int64, len(mentions))
+	for _, m := range mentions {
+		users[strings.ToLower(m.Username)] = m.UserID
+	}
+	return store.ReplaceMentions(html.EscapeString(content), func(mention, username string) string {
+		id, ok := users[strings.ToLower(username)]
+		if !ok {
+			return mention
+		}
+		return fmt.Sprintf(`<a href="%s/users/%d">%s</a>`, html.EscapeString(app.config.frontendURL), id, mention)
+	})
+}
+
+// renderPostMentions renders the content of the post, when given, and of the
+// comments of the post with their replies.
+func (app *application) renderPostMentions(ctx context.Context, postID int64, post *store.Post, comments []*store.Comment) error {
+	mentions, err := app.storage.Mentions.GetByPostID(ctx, postID)
+	if err != nil {
+		return err
+	}
+
+	// mentions of the post are grouped under 0
+	bySource := map[int64][]store.Mention{}
+	for _, m := range mentions {
+		var commentID int64
+		if m.CommentID != nil {
+			commentID = *m.CommentID
+		}
+		bySource[commentID] = append(bySource[commentID], m)
+	}
+
+	if post != nil {
+		post.ContentHTML = app.renderMentions(post.Content, bySource[0])
+	}
+	var walk func([]*store.Comment)
+	walk = func(comments []*store.Comment) {
+		for _, c := range comments {
+			c.ContentHTML = app.renderMentions(c.Content, bySource[c.ID])
+			walk(c.Replies)
+		}
+	}
+	walk(comments)
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/theluminousartemis/inkspire/internal/notifications"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+func TestCommentMentions(t *testing.T) {
+	app := newTestApplication(t, config{frontendURL: "http://example.com"})
+	client := newTestClient(t, app)
+	mentions := app.storage.Mentions.(*store.MockMentionStore)
+	notified := app.storage.Notifications.(*store.MockNotificationStore)
+
+	edit := func(t *testing.T, content string) store.Comment {
+		t.Helper()
+		var comment store.Comment
+		readData(t, client.do(t, http.MethodPatch, "/v1/posts/2/comments/3", `{"content": "`+content+`"}`), http.StatusOK, &comment)
+		return comment
+	}
+
+	comment := edit(t, "<b>thanks</b> @User2, @user1 and @nobody")
+	want := `&lt;b&gt;thanks&lt;/b&gt; <a href="http://example.com/users/2">@User2</a>, <a href="http://example.com/users/1">@user1</a> and @nobody`
+	if comment.ContentHTML != want {
+		t.Errorf("got content_html %q, want %q", comment.ContentHTML, want)
+	}
+	if len(mentions.Mentions) != 2 {
+		t.Errorf("got mentions %+v, want user2 and user1", mentions.Mentions)
+	}
+	if len(notified.Created) != 1 {
+		t.Fatalf("got notifications %+v, want one for user2 only", notified.Created)
+	}
+	n := notified.Created[0]
+	if n.UserID != 2 || n.Type != notifications.TypeMention || *n.ActorID != 1 || *n.PostID != 2 || *n.CommentID != 3 {
+		t.Errorf("got notification %+v, want a mention of user2 by user1 in comment 3", n)
+	}
+
+	edit(t, "thanks @user2 again")
+	edit(t, "no mentions")
+	if len(mentions.Mentions) != 0 {
+		t.Errorf("got mentions %+v, want none once removed", mentions.Mentions)
+	}
+	edit(t, "sorry @user2")
+	if len(notified.Created) != 1 {
+		t.Errorf("got %d notifications, want user2 notified once of the comment", len(notified.Created))
+	}
+
+	var thread CommentThread
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/2/comments/3", ""), http.StatusOK, &thread)
+	if want := `sorry <a href="http://example.com/users/2">@user2</a>`; thread.Comment.ContentHTML != want {
+		t.Errorf("got content_html %q, want %q", thread.Comment.ContentHTML, want)
+	}
+}
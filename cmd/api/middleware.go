@@ -60,40 +60,60 @@ func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			app.unauthorizedErrorResponse(w, r, fmt.Errorf("authorization header is malformed"))
-			return
-		}
-
-		token := parts[1]
-		jwtToken, err := app.authenticator.ValidateToken(token)
+		user, err := app.authenticateToken(r.Context(), authHeader)
 		if err != nil {
 			app.unauthorizedErrorResponse(w, r, err)
 			return
 		}
 
-		claims, _ := jwtToken.Claims.(jwt.MapClaims)
+		ctx := context.WithValue(r.Context(), userCtxKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
 
-		userID, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["sub"]), 10, 64)
-		if err != nil {
-			app.unauthorizedErrorResponse(w, r, err)
+// OptionalAuthTokenMiddleware lets anonymous requests through without a user in
+// the context, a token that is sent still has to be valid.
+func (app *application) OptionalAuthTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		ctx := r.Context()
-
-		user, err := app.getUser(ctx, userID)
+		user, err := app.authenticateToken(r.Context(), authHeader)
 		if err != nil {
 			app.unauthorizedErrorResponse(w, r, err)
 			return
 		}
 
-		ctx = context.WithValue(ctx, userCtxKey, user)
+		ctx := context.WithValue(r.Context(), userCtxKey, user)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+func (app *application) authenticateToken(ctx context.Context, authHeader string) (*store.User, error) {
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, fmt.Errorf("authorization header is malformed")
+	}
+
+	token := parts[1]
+	jwtToken, err := app.authenticator.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, _ := jwtToken.Claims.(jwt.MapClaims)
+
+	userID, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["sub"]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.getUser(ctx, userID)
+}
+
 func (app *application) checkPostOwnership(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := getUserFromCtx(r)
@@ -120,6 +140,39 @@ func (app *application) checkPostOwnership(requiredRole string, next http.Handle
 	})
 }
 
+// checkPostEditPermission lets users whose reputation unlocks editing edit
+// other users' posts, everyone else needs to own the post or be a moderator.
+func (app *application) checkPostEditPermission(next http.HandlerFunc) http.HandlerFunc {
+	ownership := app.checkPostOwnership("moderator", next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := getUserFromCtx(r)
+		if app.hasPrivilege(user, privilegeEditPosts) {
+			app.l.Infow("User with edit privilege has modified the post", "userID", user.ID, "username", user.Username)
+			next.ServeHTTP(w, r)
+			return
+		}
+		ownership.ServeHTTP(w, r)
+	})
+}
+
+type privilege string
+
+const (
+	privilegeDownvote  privilege = "downvote"
+	privilegeEditPosts privilege = "edit_posts"
+)
+
+func (app *application) hasPrivilege(user *store.User, p privilege) bool {
+	switch p {
+	case privilegeDownvote:
+		return user.Reputation >= app.config.reputation.downvote
+	case privilegeEditPosts:
+		return user.Reputation >= app.config.reputation.editPosts
+	default:
+		return false
+	}
+}
+
 func (app *application) checkRolePrecedence(ctx context.Context, user *store.User, roleName string) (bool, error) {
 	role, err := app.storage.Roles.GetByName(ctx, roleName)
 	if err != nil {
@@ -150,6 +203,36 @@ func (app *application) getUser(ctx context.Context, userID int64) (*store.User,
 
 }
 
+// checkRole only lets users with at least the required role through.
+func (app *application) checkRole(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := getUserFromCtx(r)
+		allowed, err := app.checkRolePrecedence(r.Context(), user, requiredRole)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		if !allowed {
+			app.l.Warnw("User lacks the required role", "userID", user.ID, "username", user.Username, "role", requiredRole)
+			app.forbiddenResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// invalidateUser drops cached users so changes such as their reputation are
+// picked up on the next request.
+func (app *application) invalidateUser(ctx context.Context, userIDs ...int64) {
+	for _, id := range userIDs {
+		if err := app.cache.Users.Delete(ctx, id); err != nil {
+			app.l.Errorw("error invalidating cached user", "id", id, "error", err)
+		}
+	}
+}
+
 func (app *application) checkcommentOwnership(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := getUserFromCtx(r)
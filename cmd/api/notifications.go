@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theluminousartemis/inkspire/internal/notifications"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+// notify stores a notification for the user, unless they caused it
+// themselves. Failures are only logged, notifications are not worth failing
+// the request that caused them.
+func (app *application) notify(ctx context.Context, userID int64, notificationType string, actorID int64, postID, commentID *int64) {
+	if userID == actorID {
+		return
+	}
+	if _, ok := notifications.Lookup(notificationType); !ok {
+		app.l.Warnw("unknown notification type", "type", notificationType)
+		return
+	}
+
+	n := &store.Notification{
+		UserID:    userID,
+		Type:      notificationType,
+		ActorID:   &actorID,
+		PostID:    postID,
+		CommentID: commentID,
+	}
+	if err := app.storage.Notifications.Create(ctx, n); err != nil {
+		app.l.Errorw("error creating notification", "userID", userID, "type", notificationType, "error", err)
+	}
+}
+
+// ListNotifications godoc
+//
+//	@Summary		Lists notifications
+//	@Description	Lists the authenticated user's notifications newest first, use next_cursor to fetch the following page
+//	@Tags			notifications
+//	@Produce		json
+//	@Param			limit	query		int		false	"Limit, 1 to 50"
+//	@Param			cursor	query		string	false	"Cursor"
+//	@Param			unread	query		bool	false	"Only list the unread notifications"
+//	@Success		200		{object}	[]store.Notification
+//	@Failure		400		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/notifications [get]
+func (app *application) listNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+
+	nq := store.NotificationQuery{
+		Limit: 20,
+	}
+
+	nq, err := nq.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(nq); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	list, next, err := app.storage.Notifications.GetByUserID(r.Context(), user.ID, nq)
+	if err != nil {
+		switch err {
+		case store.ErrInvalidCursor:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	notifications.Describe(list)
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, list, next); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// CountUnreadNotifications godoc
+//
+//	@Summary		Counts unread notifications
+//	@Description	Counts the authenticated user's unread notifications
+//	@Tags			notifications
+//	@Produce		json
+//	@Success		200	{object}	store.UnreadNotifications
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/notifications/unread [get]
+func (app *application) countUnreadNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := app.storage.Notifications.CountUnread(r.Context(), getUserFromCtx(r).ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, store.UnreadNotifications{Unread: count}); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// MarkNotificationRead godoc
+//
+//	@Summary		Marks a notification as read
+//	@Description	Marks one of the authenticated user's notifications as read
+//	@Tags			notifications
+//	@Produce		json
+//	@Param			notificationID	path		int		true	"Notification ID"
+//	@Success		204				{string}	string	"Notification read"
+//	@Failure		400				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/notifications/{notificationID}/read [put]
+func (app *application) markNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := strconv.ParseInt(chi.URLParam(r, "notificationID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.storage.Notifications.MarkRead(r.Context(), getUserFromCtx(r).ID, notificationID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notificationNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkAllNotificationsRead godoc
+//
+//	@Summary		Marks all notifications as read
+//	@Description	Marks all the authenticated user's notifications as read
+//	@Tags			notifications
+//	@Produce		json
+//	@Success		204	{string}	string	"Notifications read"
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/notifications/read [put]
+func (app *application) markAllNotificationsReadHandler(w http.ResponseWriter, r *http.Request) {
+	if err := app.storage.Notifications.MarkAllRead(r.Context(), getUserFromCtx(r).ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/theluminousartemis/inkspire/internal/notifications"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+func TestNotificationHooks(t *testing.T) {
+	app := newTestApplication(t, config{comments: commentsConfig{maxDepth: 8}})
+	client := newTestClient(t, app)
+	created := app.storage.Notifications.(*store.MockNotificationStore)
+
+	var comment store.Comment
+	readData(t, client.do(t, http.MethodPost, "/v1/posts/2/comments", `{"content": "Nice"}`), http.StatusCreated, &comment)
+	readData(t, client.do(t, http.MethodPost, "/v1/posts/1/comments", `{"content": "Thanks", "parent_id": 1}`), http.StatusCreated, nil)
+	readData(t, client.do(t, http.MethodPost, "/v1/posts/1/comments", `{"content": "On my own post"}`), http.StatusCreated, nil)
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodPut, "/v1/users/2/follow", `{}`).Code)
+	checkResponseCode(t, http.StatusConflict, client.do(t, http.MethodPut, "/v1/users/2/follow", `{}`).Code)
+
+	if comment.ID != 5 {
+		t.Fatalf("got comment %d, want 5", comment.ID)
+	}
+	type row struct {
+		userID    int64
+		kind      string
+		postID    int64
+		commentID int64
+	}
+	var got []row
+	for _, n := range created.Created {
+		r := row{userID: n.UserID, kind: n.Type}
+		if n.PostID != nil {
+			r.postID = *n.PostID
+		}
+		if n.CommentID != nil {
+			r.commentID = *n.CommentID
+		}
+		if n.ActorID == nil || *n.ActorID != 1 {
+			t.Errorf("got notification %+v, want user 1 as the actor", n)
+		}
+		got = append(got, r)
+	}
+	want := []row{
+		{2, notifications.TypeComment, 2, 5},
+		{2, notifications.TypeReply, 1, 6},
+		{2, notifications.TypeFollow, 0, 0},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got notifications %+v, want %+v", got, want)
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	created := app.storage.Notifications.(*store.MockNotificationStore)
+
+	actorID, postID := int64(2), int64(1)
+	for _, n := range []store.Notification{
+		{UserID: 1, Type: notifications.TypeComment, ActorID: &actorID, PostID: &postID},
+		{UserID: 1, Type: notifications.TypeFollow, ActorID: &actorID},
+		{UserID: 2, Type: notifications.TypeFollow},
+		{UserID: 1, Type: notifications.TypePostVote, ActorID: &actorID, PostID: &postID},
+	} {
+		if err := created.Create(context.Background(), &n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	unread := func(t *testing.T) int {
+		t.Helper()
+		var count store.UnreadNotifications
+		readData(t, client.do(t, http.MethodGet, "/v1/users/notifications/unread", ""), http.StatusOK, &count)
+		return count.Unread
+	}
+	list := func(t *testing.T, query string) ([]store.Notification, string) {
+		t.Helper()
+		var page []store.Notification
+		next := readData(t, client.do(t, http.MethodGet, "/v1/users/notifications?"+query, ""), http.StatusOK, &page)
+		return page, next
+	}
+	ids := func(ns []store.Notification) []int64 {
+		ids := make([]int64, len(ns))
+		for i, n := range ns {
+			ids[i] = n.ID
+		}
+		return ids
+	}
+
+	page, next := list(t, "limit=2")
+	if !slices.Equal(ids(page), []int64{4, 2}) || next == "" {
+		t.Fatalf("got %v with cursor %q, want notifications 4 and 2 with a cursor", ids(page), next)
+	}
+	if page[0].Message != "user2 upvoted your post" || page[1].Message != "user2 started following you" || page[0].ReadAt != nil {
+		t.Errorf("got %+v, want unread notifications described with their actor", page)
+	}
+	page, next = list(t, "limit=2&cursor="+next)
+	if !slices.Equal(ids(page), []int64{1}) || next != "" {
+		t.Errorf("got %v with cursor %q, want notification 1 on the last page", ids(page), next)
+	}
+	if got := unread(t); got != 3 {
+		t.Errorf("got %d unread, want 3", got)
+	}
+
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodPut, "/v1/users/notifications/2/read", "").Code)
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodPut, "/v1/users/notifications/2/read", "").Code)
+	checkResponseCode(t, http.StatusNotFound, client.do(t, http.MethodPut, "/v1/users/notifications/3/read", "").Code)
+	if created.Created[1].ReadAt == nil || created.Created[2].ReadAt != nil {
+		t.Errorf("got %+v, want only notification 2 read", created.Created)
+	}
+	if got := unread(t); got != 2 {
+		t.Errorf("got %d unread, want 2", got)
+	}
+	page, _ = list(t, "unread=true")
+	if !slices.Equal(ids(page), []int64{4, 1}) {
+		t.Errorf("got %v, want the unread notifications 4 and 1", ids(page))
+	}
+
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodPut, "/v1/users/notifications/read", "").Code)
+	if got := unread(t); got != 0 {
+		t.Errorf("got %d unread, want none", got)
+	}
+	if created.Created[2].ReadAt != nil {
+		t.Errorf("got %+v, want the notification of user 2 left unread", created.Created[2])
+	}
+	page, _ = list(t, "")
+	if len(page) != 3 || page[0].ReadAt == nil || page[2].ReadAt == nil {
+		t.Errorf("got %+v, want all the notifications read", page)
+	}
+}
+
+func TestNotificationErrors(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{"invalid cursor", http.MethodGet, "/v1/users/notifications?cursor=invalid"},
+		{"zero limit", http.MethodGet, "/v1/users/notifications?limit=0"},
+		{"large limit", http.MethodGet, "/v1/users/notifications?limit=51"},
+		{"invalid unread", http.MethodGet, "/v1/users/notifications?unread=maybe"},
+		{"invalid id", http.MethodPut, "/v1/users/notifications/first/read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponseCode(t, http.StatusBadRequest, client.do(t, tt.method, tt.path, "").Code)
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/theluminousartemis/inkspire/internal/badges"
 	"github.com/theluminousartemis/inkspire/internal/store"
+	"github.com/theluminousartemis/inkspire/internal/timeline"
 )
 
 type postkey string
@@ -17,7 +19,7 @@ var postCtxKey postkey = "post"
 type CreatePostPayload struct {
 	Title   string   `json:"title" validate:"required,max=100"`
 	Content string   `json:"content" validate:"required,max=10000"`
-	Tags    []string `json:"tags"`
+	Tags    []string `json:"tags" validate:"max=5,dive,max=100"`
 }
 
 // CreatePost godoc
@@ -61,6 +63,11 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.badges.Emit(badges.EventPostCreated, user.ID)
+	app.indexPosts(ctx, post.ID)
+	mentions := app.recordMentions(ctx, store.MentionSource{AuthorID: user.ID, PostID: post.ID}, post.Title, post.Content)
+	post.ContentHTML = app.renderMentions(post.Content, mentions)
+	app.updateTimelines("publish", func(t *timeline.Service) error { return t.Publish(ctx, post.ID) })
 
 	if err := app.jsonResponse(w, http.StatusCreated, post); err != nil {
 		app.internalServerError(w, r, err)
@@ -71,7 +78,7 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 // GetPost godoc
 //
 //	@Summary		Fetches a post
-//	@Description	Fetches a post by ID
+//	@Description	Fetches a post by ID along with its related posts and the first page of its comments, comments_next_cursor pages through the rest with the comments endpoint
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
@@ -84,12 +91,32 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 func (app *application) getPostHandler(w http.ResponseWriter, r *http.Request) {
 	post := getPostFromCtx(r)
 
-	comments, err := app.storage.Comments.GetByPostID(r.Context(), post.ID)
+	comments, next, err := app.storage.Comments.GetPage(r.Context(), post.ID, post.AcceptedCommentID, defaultCommentQuery)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 	post.Comments = comments
+	post.CommentsCursor = next
+
+	bookmarked, err := app.storage.Bookmarks.Exists(r.Context(), getUserFromCtx(r).ID, post.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	post.Bookmarked = bookmarked
+
+	//related posts are optional, the post is served without them on failure
+	related, err := app.relatedPosts(r.Context(), post.ID)
+	if err != nil {
+		app.l.Errorw("error fetching related posts", "postID", post.ID, "error", err)
+	}
+	post.Related = related
+
+	if err := app.renderPostMentions(r.Context(), post.ID, post, post.Comments); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 
 	if err := app.jsonResponse(w, http.StatusOK, post); err != nil {
 		app.internalServerError(w, r, err)
@@ -116,13 +143,20 @@ func (app *application) deletePostHandler(w http.ResponseWriter, r *http.Request
 	err := app.storage.Posts.Delete(ctx, post.ID)
 	if err != nil {
 		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.search.Remove(ctx, post.ID); err != nil {
+		app.l.Errorw("error removing post from the search index", "postID", post.ID, "error", err)
 	}
+	app.invalidateRelated(ctx, post.ID)
+	app.updateTimelines("retract", func(t *timeline.Service) error { return t.Retract(ctx, post.ID) })
 	w.WriteHeader(http.StatusNoContent)
 }
 
 type UpdatePostPayload struct {
-	Title   *string `json:"title" validate:"omitempty,max=100"`
-	Content *string `json:"content" validate:"omitempty,max=10000"`
+	Title   *string   `json:"title" validate:"omitempty,max=100"`
+	Content *string   `json:"content" validate:"omitempty,max=10000"`
+	Tags    *[]string `json:"tags" validate:"omitempty,max=5,dive,max=100"`
 }
 
 // UpdatePost godoc
@@ -161,6 +195,10 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 		post.Title = *payload.Title
 	}
 
+	if payload.Tags != nil {
+		post.Tags = *payload.Tags
+	}
+
 	if err := app.storage.Posts.Update(r.Context(), post); err != nil {
 		app.l.Errorf("Update failed: %v", err)
 		switch err {
@@ -172,6 +210,19 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 		default:
 			app.internalServerError(w, r, err)
 		}
+		return
+	}
+	app.indexPosts(r.Context(), post.ID)
+	app.invalidateRelated(r.Context(), post.ID)
+	if payload.Tags != nil {
+		app.updateTimelines("publish", func(t *timeline.Service) error { return t.Publish(r.Context(), post.ID) })
+	}
+	if payload.Content != nil {
+		mentions := app.recordMentions(r.Context(), store.MentionSource{AuthorID: post.UserID, PostID: post.ID}, post.Title, post.Content)
+		post.ContentHTML = app.renderMentions(post.Content, mentions)
+	} else if err := app.renderPostMentions(r.Context(), post.ID, post, nil); err != nil {
+		app.internalServerError(w, r, err)
+		return
 	}
 
 	if err := app.jsonResponse(w, http.StatusOK, post); err != nil {
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+type ReactionPayload struct {
+	Reaction string `json:"reaction" validate:"required,max=32"`
+}
+
+// TogglePostReaction godoc
+//
+//	@Summary		Toggles a reaction on a post
+//	@Description	Adds the reaction of the authenticated user to a post, or removes it if already present
+//	@Tags			posts, reactions
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int				true	"Post ID"
+//	@Param			payload	body		ReactionPayload	true	"Reaction payload"
+//	@Success		200		{object}	store.ReactionToggle
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/reactions [post]
+func (app *application) togglePostReactionHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+
+	reaction, ok := app.readReaction(w, r)
+	if !ok {
+		return
+	}
+
+	toggle, err := app.storage.Reactions.TogglePost(r.Context(), post.ID, user.ID, reaction)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, toggle); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// ToggleCommentReaction godoc
+//
+//	@Summary		Toggles a reaction on a comment
+//	@Description	Adds the reaction of the authenticated user to a comment, or removes it if already present
+//	@Tags			posts, comments, reactions
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID		path		int				true	"Post ID"
+//	@Param			commentID	path		int				true	"Comment ID"
+//	@Param			payload		body		ReactionPayload	true	"Reaction payload"
+//	@Success		200			{object}	store.ReactionToggle
+//	@Failure		400			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID}/reactions [post]
+func (app *application) toggleCommentReactionHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	comment := getCommentfromCtx(r)
+	user := getUserFromCtx(r)
+
+	if comment.PostID != post.ID {
+		app.commentNotFoundErrorResponse(w, r, store.ErrNotFound)
+		return
+	}
+
+	reaction, ok := app.readReaction(w, r)
+	if !ok {
+		return
+	}
+
+	toggle, err := app.storage.Reactions.ToggleComment(r.Context(), comment.ID, user.ID, reaction)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, toggle); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// ListPostReactions godoc
+//
+//	@Summary		Lists who reacted to a post
+//	@Description	Lists the users who reacted to a post newest first, use next_cursor to fetch the following page
+//	@Tags			posts, reactions
+//	@Produce		json
+//	@Param			postID		path		int		true	"Post ID"
+//	@Param			reaction	query		string	false	"Reaction"
+//	@Param			limit		query		int		false	"Limit"
+//	@Param			cursor		query		string	false	"Cursor"
+//	@Success		200			{object}	[]store.Reactor
+//	@Failure		400			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/reactions [get]
+func (app *application) listPostReactionsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	rq, ok := app.readReactionQuery(w, r)
+	if !ok {
+		return
+	}
+
+	reactors, next, err := app.storage.Reactions.GetPostReactors(r.Context(), post.ID, rq)
+	app.writeReactors(w, r, reactors, next, err)
+}
+
+// ListCommentReactions godoc
+//
+//	@Summary		Lists who reacted to a comment
+//	@Description	Lists the users who reacted to a comment newest first, use next_cursor to fetch the following page
+//	@Tags			posts, comments, reactions
+//	@Produce		json
+//	@Param			postID		path		int		true	"Post ID"
+//	@Param			commentID	path		int		true	"Comment ID"
+//	@Param			reaction	query		string	false	"Reaction"
+//	@Param			limit		query		int		false	"Limit"
+//	@Param			cursor		query		string	false	"Cursor"
+//	@Success		200			{object}	[]store.Reactor
+//	@Failure		400			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID}/reactions [get]
+func (app *application) listCommentReactionsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	comment := getCommentfromCtx(r)
+
+	if comment.PostID != post.ID {
+		app.commentNotFoundErrorResponse(w, r, store.ErrNotFound)
+		return
+	}
+
+	rq, ok := app.readReactionQuery(w, r)
+	if !ok {
+		return
+	}
+
+	reactors, next, err := app.storage.Reactions.GetCommentReactors(r.Context(), comment.ID, rq)
+	app.writeReactors(w, r, reactors, next, err)
+}
+
+// readReaction reads the reaction from the payload and checks it is one of the
+// configured reactions.
+func (app *application) readReaction(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var payload ReactionPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return "", false
+	}
+
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return "", false
+	}
+
+	if !slices.Contains(app.config.reactions, payload.Reaction) {
+		app.badRequestError(w, r, fmt.Errorf("unknown reaction %q, expected one of %v", payload.Reaction, app.config.reactions))
+		return "", false
+	}
+	return payload.Reaction, true
+}
+
+func (app *application) readReactionQuery(w http.ResponseWriter, r *http.Request) (store.ReactionQuery, bool) {
+	rq := store.ReactionQuery{
+		Limit: 50,
+	}
+
+	rq, err := rq.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return rq, false
+	}
+
+	if err := validate.Struct(rq); err != nil {
+		app.badRequestError(w, r, err)
+		return rq, false
+	}
+	return rq, true
+}
+
+func (app *application) writeReactors(w http.ResponseWriter, r *http.Request, reactors []store.Reactor, next string, err error) {
+	if err != nil {
+		switch err {
+		case store.ErrInvalidCursor:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, reactors, next); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
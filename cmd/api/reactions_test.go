@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+func TestToggleReactions(t *testing.T) {
+	app := newTestApplication(t, config{reactions: []string{"heart", "rocket"}})
+	client := newTestClient(t, app)
+	if _, err := app.storage.Reactions.TogglePost(context.Background(), 2, 3, "heart"); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name string
+		path string
+		body string
+		want store.ReactionToggle
+	}{
+		{"reacts to a post", "/v1/posts/2/reactions", `{"reaction": "heart"}`, store.ReactionToggle{Reaction: "heart", Reacted: true, Count: 2}},
+		{"adds another reaction", "/v1/posts/2/reactions", `{"reaction": "rocket"}`, store.ReactionToggle{Reaction: "rocket", Reacted: true, Count: 1}},
+		{"removes a reaction", "/v1/posts/2/reactions", `{"reaction": "heart"}`, store.ReactionToggle{Reaction: "heart", Reacted: false, Count: 1}},
+		{"reacts to a comment", "/v1/posts/1/comments/1/reactions", `{"reaction": "heart"}`, store.ReactionToggle{Reaction: "heart", Reacted: true, Count: 1}},
+	}
+	for _, step := range steps {
+		var toggle store.ReactionToggle
+		readData(t, client.do(t, http.MethodPost, step.path, step.body), http.StatusOK, &toggle)
+		if toggle != step.want {
+			t.Errorf("%s: got %+v, want %+v", step.name, toggle, step.want)
+		}
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{"refuses an unconfigured reaction", "/v1/posts/2/reactions", `{"reaction": "eyes"}`, http.StatusBadRequest},
+		{"refuses an empty reaction", "/v1/posts/2/reactions", `{"reaction": ""}`, http.StatusBadRequest},
+		{"refuses comments of another post", "/v1/posts/2/comments/1/reactions", `{"reaction": "heart"}`, http.StatusNotFound},
+		{"refuses missing posts", "/v1/posts/3/reactions", `{"reaction": "heart"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponseCode(t, tt.want, client.do(t, http.MethodPost, tt.path, tt.body).Code)
+		})
+	}
+
+	var reactors []store.Reactor
+	readData(t, client.do(t, http.MethodGet, "/v1/posts/2/reactions", ""), http.StatusOK, &reactors)
+	if len(reactors) != 2 || reactors[0].UserID != 1 || reactors[0].Reaction != "rocket" || reactors[1].UserID != 3 {
+		t.Errorf("got reactors %+v, want the rocket of user 1 then the heart of user 3", reactors)
+	}
+}
+
+func TestListReactors(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	ctx := context.Background()
+	for userID := int64(3); userID <= 5; userID++ {
+		if _, err := app.storage.Reactions.ToggleComment(ctx, 1, userID, "heart"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := app.storage.Reactions.ToggleComment(ctx, 1, 3, "rocket"); err != nil {
+		t.Fatal(err)
+	}
+
+	// reactions created at the same time page by their ids
+	var users []int64
+	path := "/v1/posts/1/comments/1/reactions?reaction=heart&limit=2"
+	for page := 0; path != ""; page++ {
+		if page == 3 {
+			t.Fatal("got more than 2 pages of reactors")
+		}
+		var reactors []store.Reactor
+		next := readData(t, client.do(t, http.MethodGet, path, ""), http.StatusOK, &reactors)
+		for _, r := range reactors {
+			users = append(users, r.UserID)
+		}
+		path = ""
+		if next != "" {
+			path = "/v1/posts/1/comments/1/reactions?reaction=heart&limit=2&cursor=" + next
+		}
+	}
+	if len(users) != 3 || users[0] != 5 || users[1] != 4 || users[2] != 3 {
+		t.Errorf("got reactors %v, want users 5, 4 and 3", users)
+	}
+
+	checkResponseCode(t, http.StatusBadRequest, client.do(t, http.MethodGet, "/v1/posts/1/comments/1/reactions?cursor=invalid", "").Code)
+	checkResponseCode(t, http.StatusBadRequest, client.do(t, http.MethodGet, "/v1/posts/1/reactions?limit=101", "").Code)
+	checkResponseCode(t, http.StatusNotFound, client.do(t, http.MethodGet, "/v1/posts/2/comments/1/reactions", "").Code)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+// relatedPostsSize is the number of related posts listed for a post
+const relatedPostsSize = 10
+
+// GetRelatedPosts godoc
+//
+//	@Summary		Fetches the related posts
+//	@Description	Lists the questions most similar to the post by their tags and titles, most similar first
+//	@Tags			posts
+//	@Produce		json
+//	@Param			id	path		int	true	"Post ID"
+//	@Success		200	{object}	[]store.RelatedPost
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{id}/related [get]
+func (app *application) getRelatedPostsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	related, err := app.relatedPosts(r.Context(), post.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, related); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// relatedPosts returns the cached related posts of the post, computing and
+// caching them on a miss. A failing cache is only logged and falls back to the
+// database.
+func (app *application) relatedPosts(ctx context.Context, postID int64) ([]store.RelatedPost, error) {
+	related, ok, err := app.cache.Related.Get(ctx, postID)
+	if err != nil {
+		app.l.Errorw("error fetching cached related posts", "postID", postID, "error", err)
+	} else if ok {
+		return related, nil
+	}
+
+	related, err = app.storage.Posts.GetRelated(ctx, postID, relatedPostsSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := app.cache.Related.Set(ctx, postID, related); err != nil {
+		app.l.Errorw("error caching related posts", "postID", postID, "error", err)
+	}
+	return related, nil
+}
+
+// invalidateRelated drops the cached related posts of the posts after they
+// changed, along with the cached lists they appear in. Failures are only
+// logged, the lists expire on their own.
+func (app *application) invalidateRelated(ctx context.Context, postIDs ...int64) {
+	if err := app.cache.Related.Delete(ctx, postIDs...); err != nil {
+		app.l.Errorw("error invalidating related posts", "postIDs", postIDs, "error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+// Search godoc
+//
+//	@Summary		Searches posts
+//	@Description	Full-text search over the title, tags, content and comments of posts, ranked by relevance with highlighted snippets. Terms prefixed with - exclude posts, the postgres backend also accepts quoted phrases and OR while the index backend tolerates typos. Works without authentication
+//	@Tags			search
+//	@Produce		json
+//	@Param			q		query		string	true	"Search query"
+//	@Param			tags	query		string	false	"Tags"
+//	@Param			author	query		string	false	"Username of the author"
+//	@Param			since	query		string	false	"Only posts created at or after, RFC 3339 or YYYY-MM-DD HH:MM:SS"
+//	@Param			until	query		string	false	"Only posts created before, RFC 3339 or YYYY-MM-DD HH:MM:SS"
+//	@Param			limit	query		int		false	"Limit"
+//	@Param			offset	query		int		false	"Offset"
+//	@Success		200		{object}	[]store.SearchResult
+//	@Failure		400		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/search [get]
+func (app *application) searchHandler(w http.ResponseWriter, r *http.Request) {
+	sq := store.SearchQuery{
+		Limit: 20,
+		Tags:  []string{},
+	}
+
+	sq, err := sq.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(sq); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	if sq.Tags, err = app.storage.Tags.Canonical(ctx, sq.Tags); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	results, err := app.search.Search(ctx, sq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, results); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// indexPosts brings the search index in sync with the posts after they or
+// their comments changed. Failures are only logged, the index catches up on
+// the next change or rebuild.
+func (app *application) indexPosts(ctx context.Context, postIDs ...int64) {
+	if err := app.search.Index(ctx, postIDs...); err != nil {
+		app.l.Errorw("error indexing posts", "postIDs", postIDs, "error", err)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+func TestSearch(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	search := app.storage.Search.(*store.MockSearchStore)
+
+	query := url.Values{
+		"q":      {"  F  "},
+		"tags":   {"GoLang,rust"},
+		"author": {"alice"},
+		"since":  {"2026-01-01T01:00:00+01:00"},
+		"until":  {"2026-02-01 00:00:00"},
+		"limit":  {"5"},
+	}
+	var results []store.SearchResult
+	readData(t, client.do(t, http.MethodGet, "/v1/search?"+query.Encode(), ""), http.StatusOK, &results)
+	if len(results) != 2 || results[0].ID != 4 || results[1].ID != 1 || results[0].Highlight != "Fourth" {
+		t.Errorf("got %+v, want posts 4 and 1", results)
+	}
+
+	sq := search.Query
+	if sq.Query != "F" || !slices.Equal(sq.Tags, []string{"go", "rust"}) || sq.Author != "alice" || sq.Limit != 5 || sq.Offset != 0 {
+		t.Errorf("got query %+v, want the trimmed query and canonical tags", sq)
+	}
+	if want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC); sq.Since == nil || !sq.Since.Equal(want) {
+		t.Errorf("got since %v, want %v", sq.Since, want)
+	}
+	if want := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC); sq.Until == nil || !sq.Until.Equal(want) {
+		t.Errorf("got until %v, want %v", sq.Until, want)
+	}
+
+	readData(t, client.do(t, http.MethodGet, "/v1/search?q=f&offset=1", ""), http.StatusOK, &results)
+	if len(results) != 1 || results[0].ID != 1 || search.Query.Limit != 20 {
+		t.Errorf("got %+v with limit %d, want post 1 with the default limit", results, search.Query.Limit)
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"requires a query", ""},
+		{"refuses a blank query", "q=%20%20"},
+		{"refuses a date without a time", "q=redis&since=2026-01-01"},
+		{"refuses an invalid date", "q=redis&until=yesterday"},
+		{"refuses an empty range", "q=redis&since=2026-01-02T00:00:00Z&until=2026-01-01T00:00:00Z"},
+		{"refuses a large limit", "q=redis&limit=51"},
+		{"refuses too many tags", "q=redis&tags=a,b,c,d,e,f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponseCode(t, http.StatusBadRequest, client.do(t, http.MethodGet, "/v1/search?"+tt.query, "").Code)
+		})
+	}
+}
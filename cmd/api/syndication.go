@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theluminousartemis/inkspire/internal/store"
+	"github.com/theluminousartemis/inkspire/internal/syndication"
+)
+
+// syndicationSize is the number of posts listed in the Atom and RSS feeds
+const syndicationSize = 20
+
+var errUnknownFeedFormat = errors.New("format must be atom or rss")
+
+// LatestFeed godoc
+//
+//	@Summary		Syndicates the latest posts
+//	@Description	Atom 1.0 or RSS 2.0 feed of the latest posts of the site. Supports conditional requests with If-None-Match and If-Modified-Since
+//	@Tags			syndication
+//	@Produce		xml
+//	@Param			format	path		string	true	"atom or rss"
+//	@Success		200		{string}	string
+//	@Success		304		{string}	string
+//	@Failure		400		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/feeds/latest/{format} [get]
+func (app *application) latestFeedHandler(w http.ResponseWriter, r *http.Request) {
+	feed := syndication.Feed{
+		Title:       "Inkspire",
+		Description: "The latest questions on Inkspire",
+		Link:        app.config.frontendURL,
+	}
+	app.syndicate(w, r, feed, 0, "")
+}
+
+// UserPostsFeed godoc
+//
+//	@Summary		Syndicates the posts of a user
+//	@Description	Atom 1.0 or RSS 2.0 feed of the latest posts of the user. Supports conditional requests with If-None-Match and If-Modified-Since
+//	@Tags			syndication
+//	@Produce		xml
+//	@Param			userID	path		int		true	"User ID"
+//	@Param			format	path		string	true	"atom or rss"
+//	@Success		200		{string}	string
+//	@Success		304		{string}	string
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/feeds/users/{userID}/{format} [get]
+func (app *application) userPostsFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	user, err := app.getUser(r.Context(), userID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.userNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	feed := syndication.Feed{
+		Title:       fmt.Sprintf("%s on Inkspire", user.Username),
+		Description: fmt.Sprintf("The latest questions of %s", user.Username),
+		Link:        fmt.Sprintf("%s/users/%d", app.config.frontendURL, user.ID),
+	}
+	app.syndicate(w, r, feed, user.ID, "")
+}
+
+// TagPostsFeed godoc
+//
+//	@Summary		Syndicates the posts of a tag
+//	@Description	Atom 1.0 or RSS 2.0 feed of the latest posts tagged with the tag. Supports conditional requests with If-None-Match and If-Modified-Since
+//	@Tags			syndication
+//	@Produce		xml
+//	@Param			tag		path		string	true	"Tag slug"
+//	@Param			format	path		string	true	"atom or rss"
+//	@Success		200		{string}	string
+//	@Success		304		{string}	string
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/feeds/tags/{tag}/{format} [get]
+func (app *application) tagPostsFeedHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+
+	feed := syndication.Feed{
+		Title:       fmt.Sprintf("%s on Inkspire", tag.Slug),
+		Description: fmt.Sprintf("The latest questions tagged %s", tag.Slug),
+		Link:        fmt.Sprintf("%s/tags/%s", app.config.frontendURL, tag.Slug),
+	}
+	app.syndicate(w, r, feed, 0, tag.Slug)
+}
+
+// syndicate fills the feed with the latest posts matching the author and tag
+// and writes it in the format of the request. The ETag hashes the document so
+// any change to the listed posts changes it, and Last-Modified is the last
+// update of the posts; http.ServeContent answers the conditional requests.
+func (app *application) syndicate(w http.ResponseWriter, r *http.Request, feed syndication.Feed, authorID int64, tag string) {
+	format := chi.URLParam(r, "format")
+	if format != "atom" && format != "rss" {
+		app.badRequestError(w, r, errUnknownFeedFormat)
+		return
+	}
+
+	posts, err := app.storage.Posts.GetPublished(r.Context(), authorID, tag, syndicationSize)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	feed.Self = app.requestURL(r)
+	for _, p := range posts {
+		feed.Items = append(feed.Items, syndication.Item{
+			Title:      p.Title,
+			Link:       fmt.Sprintf("%s/posts/%d", app.config.frontendURL, p.ID),
+			Author:     p.User.Username,
+			Categories: p.Tags,
+			Published:  p.CreatedAt,
+			Updated:    p.UpdatedAt,
+			Content:    syndication.RenderText(p.Content),
+		})
+	}
+
+	var body []byte
+	var contentType string
+	if format == "atom" {
+		body, err = feed.Atom()
+		contentType = "application/atom+xml; charset=utf-8"
+	} else {
+		body, err = feed.RSS()
+		contentType = "application/rss+xml; charset=utf-8"
+	}
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	http.ServeContent(w, r, "", feed.Updated(), bytes.NewReader(body))
+}
+
+// requestURL is the absolute URL of the request on the API host. Behind a
+// proxy terminating TLS the scheme is read from X-Forwarded-Proto, which is
+// trusted like the client address headers read by RealIP.
+func (app *application) requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	if proto = strings.ToLower(strings.TrimSpace(proto)); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, app.config.apiURL, r.URL.RequestURI())
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestURL(t *testing.T) {
+	app := newTestApplication(t, config{apiURL: "api.example.com"})
+
+	tests := []struct {
+		name  string
+		tls   bool
+		proto string
+		want  string
+	}{
+		{"plain http", false, "", "http://api.example.com/v1/feeds?format=atom"},
+		{"tls", true, "", "https://api.example.com/v1/feeds?format=atom"},
+		{"tls terminated by a proxy", false, "https", "https://api.example.com/v1/feeds?format=atom"},
+		{"first proxy wins", false, "HTTPS, http", "https://api.example.com/v1/feeds?format=atom"},
+		{"unknown scheme ignored", false, "ftp", "http://api.example.com/v1/feeds?format=atom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/v1/feeds?format=atom", nil)
+			if tt.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+			if tt.proto != "" {
+				r.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if got := app.requestURL(r); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
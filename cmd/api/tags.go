@@ -0,0 +1,567 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/theluminousartemis/inkspire/internal/store"
+	"github.com/theluminousartemis/inkspire/internal/timeline"
+)
+
+type tagkey string
+
+var tagCtxKey tagkey = "tag"
+
+type UpdateTagPayload struct {
+	Description string `json:"description" validate:"max=5000"`
+}
+
+type TagSynonymPayload struct {
+	Synonym string `json:"synonym" validate:"required,max=100"`
+}
+
+type RenameTagPayload struct {
+	Slug string `json:"slug" validate:"required,max=100"`
+}
+
+type MergeTagPayload struct {
+	Into string `json:"into" validate:"required,max=100"`
+}
+
+// ListTags godoc
+//
+//	@Summary		Lists tags
+//	@Description	Lists the canonical tags, most used first
+//	@Tags			tags
+//	@Produce		json
+//	@Param			search	query		string	false	"Slug prefix"
+//	@Param			limit	query		int		false	"Limit"
+//	@Param			offset	query		int		false	"Offset"
+//	@Success		200		{object}	[]store.Tag
+//	@Failure		400		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags [get]
+func (app *application) listTagsHandler(w http.ResponseWriter, r *http.Request) {
+	tq := store.TagQuery{
+		Limit: 50,
+	}
+
+	tq, err := tq.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(tq); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	tags, err := app.storage.Tags.GetAll(r.Context(), tq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, tags); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// GetTag godoc
+//
+//	@Summary		Fetches a tag
+//	@Description	Fetches a tag by its slug or one of its synonyms
+//	@Tags			tags
+//	@Produce		json
+//	@Param			tag	path		string	true	"Tag slug"
+//	@Success		200	{object}	store.Tag
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag} [get]
+func (app *application) getTagHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+
+	if err := app.jsonResponse(w, http.StatusOK, tag); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// GetTagPosts godoc
+//
+//	@Summary		Lists the posts of a tag
+//	@Description	Lists the posts tagged with the tag, accepts the same filters as the feed
+//	@Tags			tags, feed
+//	@Produce		json
+//	@Param			tag			path		string	true	"Tag slug"
+//	@Param			limit		query		int		false	"Limit"
+//	@Param			offset		query		int		false	"Offset"
+//	@Param			cursor		query		string	false	"Cursor of the next page, replaces offset for the asc and desc sorts"
+//	@Param			sort		query		string	false	"Sort (asc, desc, top, updated or comments)"
+//	@Param			since		query		string	false	"Only posts created at or after, RFC 3339 or YYYY-MM-DD HH:MM:SS"
+//	@Param			until		query		string	false	"Only posts created before, RFC 3339 or YYYY-MM-DD HH:MM:SS"
+//	@Param			tags		query		string	false	"Tags"
+//	@Param			search		query		string	false	"Search"
+//	@Param			answered	query		bool	false	"Only answered (true) or unanswered (false) questions"
+//	@Param			bountied	query		bool	false	"Only questions with an open bounty"
+//	@Success		200			{object}	[]store.PostWithMetadata
+//	@Failure		400			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag}/posts [get]
+func (app *application) getTagPostsHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+	user := getUserFromCtx(r)
+
+	fq, ok := app.readFeedQuery(w, r)
+	if !ok {
+		return
+	}
+	if fq.Sort == "ranked" {
+		app.badRequestError(w, r, errors.New("the ranked sort is only available on the user feed"))
+		return
+	}
+
+	posts, next, err := app.storage.Posts.GetTagFeed(r.Context(), user.ID, tag.Slug, fq)
+	if err != nil {
+		switch err {
+		case store.ErrInvalidCursor:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.paginatedJSONResponse(w, http.StatusOK, posts, next); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// UpdateTag godoc
+//
+//	@Summary		Updates a tag
+//	@Description	Updates the description of a tag, moderator only
+//	@Tags			tags, moderation
+//	@Accept			json
+//	@Produce		json
+//	@Param			tag		path		string				true	"Tag slug"
+//	@Param			payload	body		UpdateTagPayload	true	"Tag payload"
+//	@Success		200		{object}	store.Tag
+//	@Failure		400		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag} [patch]
+func (app *application) updateTagHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+
+	var payload UpdateTagPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	tag.Description = payload.Description
+	if err := app.storage.Tags.Update(r.Context(), tag); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.tagNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, tag); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// AddTagSynonym godoc
+//
+//	@Summary		Adds a tag synonym
+//	@Description	Makes a slug an alias of the tag, posts created or updated with it get the canonical tag, moderator only
+//	@Tags			tags, moderation
+//	@Accept			json
+//	@Produce		json
+//	@Param			tag		path		string				true	"Tag slug"
+//	@Param			payload	body		TagSynonymPayload	true	"Synonym payload"
+//	@Success		200		{object}	store.Tag
+//	@Failure		400		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		409		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag}/synonyms [post]
+func (app *application) addTagSynonymHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+
+	var payload TagSynonymPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	synonym, ok := app.readSlug(w, r, payload.Synonym)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	if err := app.storage.Tags.AddSynonym(ctx, tag.ID, synonym); err != nil {
+		switch err {
+		case store.ErrConflict:
+			app.conflictResponse(w, r, errors.New("slug is already a tag or a synonym, merge the tags instead"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.respondWithTag(w, r, ctx, tag.Slug)
+}
+
+// RemoveTagSynonym godoc
+//
+//	@Summary		Removes a tag synonym
+//	@Description	Removes a synonym from the tag, moderator only
+//	@Tags			tags, moderation
+//	@Produce		json
+//	@Param			tag		path		string	true	"Tag slug"
+//	@Param			synonym	path		string	true	"Synonym"
+//	@Success		204		{string}	string	"Synonym removed"
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag}/synonyms/{synonym} [delete]
+func (app *application) removeTagSynonymHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+	synonym := store.NormalizeTag(chi.URLParam(r, "synonym"))
+
+	if err := app.storage.Tags.RemoveSynonym(r.Context(), tag.ID, synonym); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.tagNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RenameTag godoc
+//
+//	@Summary		Renames a tag
+//	@Description	Renames a tag on every post using it, the old slug is kept as a synonym, moderator only
+//	@Tags			tags, moderation
+//	@Accept			json
+//	@Produce		json
+//	@Param			tag		path		string				true	"Tag slug"
+//	@Param			payload	body		RenameTagPayload	true	"Rename payload"
+//	@Success		200		{object}	store.Tag
+//	@Failure		400		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		409		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag}/rename [put]
+func (app *application) renameTagHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+	user := getUserFromCtx(r)
+
+	var payload RenameTagPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	slug, ok := app.readSlug(w, r, payload.Slug)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	previous := tag.Slug
+	if slug != previous {
+		retagged, err := app.storage.Tags.Rename(ctx, tag, slug)
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				app.tagNotFoundErrorResponse(w, r, err)
+			case store.ErrConflict:
+				app.conflictResponse(w, r, errors.New("slug is already used by another tag, merge the tags instead"))
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+		app.l.Infow("Moderator/Admin has renamed the tag", "userID", user.ID, "from", previous, "to", slug)
+		app.indexPosts(ctx, retagged...)
+		app.invalidateRelated(ctx, retagged...)
+	}
+
+	app.respondWithTag(w, r, ctx, slug)
+}
+
+// MergeTag godoc
+//
+//	@Summary		Merges a tag into another
+//	@Description	Retags the posts of the tag with the target tag and makes the tag a synonym of it, moderator only
+//	@Tags			tags, moderation
+//	@Accept			json
+//	@Produce		json
+//	@Param			tag		path		string			true	"Tag slug"
+//	@Param			payload	body		MergeTagPayload	true	"Merge payload"
+//	@Success		200		{object}	store.Tag
+//	@Failure		400		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag}/merge [put]
+func (app *application) mergeTagHandler(w http.ResponseWriter, r *http.Request) {
+	tag := getTagFromCtx(r)
+	user := getUserFromCtx(r)
+
+	var payload MergeTagPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	target, err := app.storage.Tags.GetBySlug(ctx, payload.Into)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.tagNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if target.ID == tag.ID {
+		app.badRequestError(w, r, errors.New("a tag cannot be merged into itself"))
+		return
+	}
+
+	retagged, err := app.storage.Tags.Merge(ctx, tag.ID, target.ID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.tagNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.l.Infow("Moderator/Admin has merged the tag", "userID", user.ID, "from", tag.Slug, "into", target.Slug)
+	app.indexPosts(ctx, retagged...)
+	app.invalidateRelated(ctx, retagged...)
+
+	app.respondWithTag(w, r, ctx, target.Slug)
+}
+
+// FollowTag godoc
+//
+//	@Summary		Follows a tag
+//	@Description	Follows a tag so its posts show up in the feed, replaces an ignore of the tag
+//	@Tags			tags
+//	@Produce		json
+//	@Param			tag	path		string	true	"Tag slug"
+//	@Success		204	{string}	string	"Tag followed"
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag}/follow [put]
+func (app *application) followTagHandler(w http.ResponseWriter, r *http.Request) {
+	app.subscribeTag(w, r, store.TagFollow)
+}
+
+// UnfollowTag godoc
+//
+//	@Summary		Unfollows a tag
+//	@Description	Unfollows a tag
+//	@Tags			tags
+//	@Produce		json
+//	@Param			tag	path		string	true	"Tag slug"
+//	@Success		204	{string}	string	"Tag unfollowed"
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag}/unfollow [put]
+func (app *application) unfollowTagHandler(w http.ResponseWriter, r *http.Request) {
+	app.unsubscribeTag(w, r, store.TagFollow)
+}
+
+// IgnoreTag godoc
+//
+//	@Summary		Ignores a tag
+//	@Description	Ignores a tag so posts of others tagged with it are hidden from the feed, replaces a follow of the tag
+//	@Tags			tags
+//	@Produce		json
+//	@Param			tag	path		string	true	"Tag slug"
+//	@Success		204	{string}	string	"Tag ignored"
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag}/ignore [put]
+func (app *application) ignoreTagHandler(w http.ResponseWriter, r *http.Request) {
+	app.subscribeTag(w, r, store.TagIgnore)
+}
+
+// UnignoreTag godoc
+//
+//	@Summary		Stops ignoring a tag
+//	@Description	Stops ignoring a tag
+//	@Tags			tags
+//	@Produce		json
+//	@Param			tag	path		string	true	"Tag slug"
+//	@Success		204	{string}	string	"Tag no longer ignored"
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/tags/{tag}/unignore [put]
+func (app *application) unignoreTagHandler(w http.ResponseWriter, r *http.Request) {
+	app.unsubscribeTag(w, r, store.TagIgnore)
+}
+
+// ListTagSubscriptions godoc
+//
+//	@Summary		Lists the tags of the user
+//	@Description	Lists the tags the authenticated user follows or ignores
+//	@Tags			tags, users
+//	@Produce		json
+//	@Success		200	{object}	[]store.TagSubscription
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/tags [get]
+func (app *application) listTagSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+
+	subscriptions, err := app.storage.Tags.GetSubscriptions(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, subscriptions); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+func (app *application) subscribeTag(w http.ResponseWriter, r *http.Request, kind string) {
+	tag := getTagFromCtx(r)
+	user := getUserFromCtx(r)
+
+	if err := app.storage.Tags.Subscribe(r.Context(), user.ID, tag.ID, kind); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.updateTimelines("reset", func(t *timeline.Service) error { return t.Reset(r.Context(), user.ID) })
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) unsubscribeTag(w http.ResponseWriter, r *http.Request, kind string) {
+	tag := getTagFromCtx(r)
+	user := getUserFromCtx(r)
+
+	if err := app.storage.Tags.Unsubscribe(r.Context(), user.ID, tag.ID, kind); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.tagNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.updateTimelines("reset", func(t *timeline.Service) error { return t.Reset(r.Context(), user.ID) })
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readSlug normalizes a slug given by a moderator and rejects the ones left
+// empty by the normalization.
+func (app *application) readSlug(w http.ResponseWriter, r *http.Request, raw string) (string, bool) {
+	slug := store.NormalizeTag(raw)
+	if slug == "" {
+		app.badRequestError(w, r, errors.New("tag must contain at least one letter or digit"))
+		return "", false
+	}
+	return slug, true
+}
+
+func (app *application) respondWithTag(w http.ResponseWriter, r *http.Request, ctx context.Context, slug string) {
+	tag, err := app.storage.Tags.GetBySlug(ctx, slug)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, tag); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+func (app *application) tagsContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		tag, err := app.storage.Tags.GetBySlug(ctx, chi.URLParam(r, "tag"))
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				app.tagNotFoundErrorResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+		ctx = context.WithValue(ctx, tagCtxKey, tag)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getTagFromCtx(r *http.Request) *store.Tag {
+	tag, _ := r.Context().Value(tagCtxKey).(*store.Tag)
+	return tag
+}
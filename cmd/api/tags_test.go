@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+	"github.com/theluminousartemis/inkspire/internal/store/cache"
+)
+
+func TestListTags(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+
+	var tags []store.Tag
+	readData(t, client.do(t, http.MethodGet, "/v1/tags", ""), http.StatusOK, &tags)
+	if len(tags) != 2 || tags[0].Slug != "go" || tags[0].UsageCount != 2 || tags[1].Slug != "rust" || tags[1].UsageCount != 1 {
+		t.Errorf("got %+v, want go used twice before rust", tags)
+	}
+
+	readData(t, client.do(t, http.MethodGet, "/v1/tags?search=ru", ""), http.StatusOK, &tags)
+	if len(tags) != 1 || tags[0].Slug != "rust" {
+		t.Errorf("got %+v, want only rust", tags)
+	}
+
+	var tag store.Tag
+	readData(t, client.do(t, http.MethodGet, "/v1/tags/GoLang", ""), http.StatusOK, &tag)
+	if tag.Slug != "go" || !slices.Equal(tag.Synonyms, []string{"golang"}) {
+		t.Errorf("got %+v, want the synonym to resolve to go", tag)
+	}
+	checkResponseCode(t, http.StatusNotFound, client.do(t, http.MethodGet, "/v1/tags/python", "").Code)
+}
+
+func TestModerateTags(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	tags := app.storage.Tags.(*store.MockTagStore)
+
+	checkResponseCode(t, http.StatusForbidden, client.do(t, http.MethodPut, "/v1/tags/rust/rename", `{"slug": "rust-lang"}`).Code)
+	if tags.Tags[2].Slug != "rust" {
+		t.Fatalf("got %+v, want a user not to rename tags", tags.Tags[2])
+	}
+
+	app.cache.Users.(*cache.MockUserStore).Role = store.Role{ID: 2, Name: "moderator", Level: 2}
+
+	var tag store.Tag
+	readData(t, client.do(t, http.MethodPost, "/v1/tags/rust/synonyms", `{"synonym": "RS"}`), http.StatusOK, &tag)
+	if tag.Slug != "rust" || !slices.Equal(tag.Synonyms, []string{"rs"}) {
+		t.Errorf("got %+v, want rs as a synonym of rust", tag)
+	}
+	checkResponseCode(t, http.StatusConflict, client.do(t, http.MethodPost, "/v1/tags/rust/synonyms", `{"synonym": "golang"}`).Code)
+	checkResponseCode(t, http.StatusConflict, client.do(t, http.MethodPost, "/v1/tags/rust/synonyms", `{"synonym": "go"}`).Code)
+
+	readData(t, client.do(t, http.MethodPut, "/v1/tags/rust/rename", `{"slug": "Rust Lang"}`), http.StatusOK, &tag)
+	if tag.Slug != "rust-lang" || !slices.Equal(tag.Synonyms, []string{"rs", "rust"}) || tag.UsageCount != 1 {
+		t.Errorf("got %+v, want rust renamed to rust-lang and kept as a synonym", tag)
+	}
+	if !slices.Equal(tags.PostTags[2], []string{"go", "rust-lang"}) {
+		t.Errorf("got post 2 tagged %v, want it retagged with rust-lang", tags.PostTags[2])
+	}
+	checkResponseCode(t, http.StatusConflict, client.do(t, http.MethodPut, "/v1/tags/rust-lang/rename", `{"slug": "go"}`).Code)
+
+	checkResponseCode(t, http.StatusBadRequest, client.do(t, http.MethodPut, "/v1/tags/rust-lang/merge", `{"into": "rs"}`).Code)
+	checkResponseCode(t, http.StatusNotFound, client.do(t, http.MethodPut, "/v1/tags/rust-lang/merge", `{"into": "python"}`).Code)
+
+	readData(t, client.do(t, http.MethodPut, "/v1/tags/rust/merge", `{"into": "golang"}`), http.StatusOK, &tag)
+	if tag.Slug != "go" || !slices.Equal(tag.Synonyms, []string{"golang", "rs", "rust", "rust-lang"}) || tag.UsageCount != 2 {
+		t.Errorf("got %+v, want go with the synonyms of rust-lang", tag)
+	}
+	if _, ok := tags.Tags[2]; ok || !slices.Equal(tags.PostTags[2], []string{"go"}) {
+		t.Errorf("got tags %v and post 2 tagged %v, want rust-lang deleted and post 2 tagged once", tags.Tags, tags.PostTags[2])
+	}
+
+	checkResponseCode(t, http.StatusNoContent, client.do(t, http.MethodDelete, "/v1/tags/go/synonyms/RS", "").Code)
+	checkResponseCode(t, http.StatusNotFound, client.do(t, http.MethodDelete, "/v1/tags/go/synonyms/rs", "").Code)
+	checkResponseCode(t, http.StatusNotFound, client.do(t, http.MethodGet, "/v1/tags/rs", "").Code)
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/theluminousartemis/inkspire/internal/auth"
+	"github.com/theluminousartemis/inkspire/internal/badges"
+	"github.com/theluminousartemis/inkspire/internal/mailer"
 	"github.com/theluminousartemis/inkspire/internal/ratelimiter"
+	"github.com/theluminousartemis/inkspire/internal/search"
 	"github.com/theluminousartemis/inkspire/internal/store"
 	"github.com/theluminousartemis/inkspire/internal/store/cache"
 	"go.uber.org/zap"
@@ -23,6 +28,9 @@ func newTestApplication(t *testing.T, cfg config) *application {
 		cfg.ratelimiter.RequestsPerTimeFrame,
 		cfg.ratelimiter.Timeframe,
 	)
+	badges := badges.NewEngine(mockStore, logger, 1, 10)
+	badges.Start()
+	t.Cleanup(badges.Stop)
 	return &application{
 		l:             logger,
 		storage:       mockStore,
@@ -30,6 +38,9 @@ func newTestApplication(t *testing.T, cfg config) *application {
 		config:        cfg,
 		authenticator: auth,
 		rateLimiter:   ratelimiter,
+		mailer:        &mailer.MockClient{},
+		badges:        badges,
+		search:        search.NewPostgres(mockStore),
 	}
 }
 
@@ -39,6 +50,52 @@ func executeRequest(req *http.Request, mux http.Handler) *httptest.ResponseRecor
 	return rr
 }
 
+// testClient sends requests to the application with the test token, the one
+// of user 1 of the mock stores.
+type testClient struct {
+	mux   http.Handler
+	token string
+}
+
+func newTestClient(t *testing.T, app *application) *testClient {
+	t.Helper()
+	token, err := app.authenticator.GenerateToken(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testClient{mux: app.mount(), token: token}
+}
+
+func (c *testClient) do(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	return executeRequest(req, c.mux)
+}
+
+// readData checks the response code and decodes the data of the response into
+// data, it returns the cursor of the next page.
+func readData(t *testing.T, rr *httptest.ResponseRecorder, status int, data any) string {
+	t.Helper()
+	checkResponseCode(t, status, rr.Code)
+	var envelope struct {
+		Data       json.RawMessage `json:"data"`
+		NextCursor string          `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decoding %s: %v", rr.Body, err)
+	}
+	if data != nil {
+		if err := json.Unmarshal(envelope.Data, data); err != nil {
+			t.Fatalf("decoding %s: %v", envelope.Data, err)
+		}
+	}
+	return envelope.NextCursor
+}
+
 func checkResponseCode(t *testing.T, expected, actual int) {
 	if expected != actual {
 		t.Errorf("expected response code %d got %d", expected, actual)
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+	"github.com/theluminousartemis/inkspire/internal/timeline"
+)
+
+// timelineFeed serves a page of the user feed from their timeline. ok is false
+// when timelines are disabled, cannot serve the query or fail, the feed is then
+// read from the database.
+func (app *application) timelineFeed(ctx context.Context, userID int64, fq store.PagintatedFeedQuery) ([]store.PostWithMetadata, string, bool) {
+	if app.timelines == nil || !timeline.Supports(fq) {
+		return nil, "", false
+	}
+	feed, next, ok, err := app.timelines.Feed(ctx, userID, fq)
+	if err != nil {
+		app.l.Errorw("error reading timeline, falling back to the database", "userID", userID, "error", err)
+		return nil, "", false
+	}
+	return feed, next, ok
+}
+
+// updateTimelines applies a change to the timelines when they are enabled.
+// Failures are only logged: reads drop the posts that left a feed and the
+// timelines are rebuilt once they expire.
+func (app *application) updateTimelines(action string, update func(*timeline.Service) error) {
+	if app.timelines == nil {
+		return
+	}
+	if err := update(app.timelines); err != nil {
+		app.l.Errorw("error updating timelines", "action", action, "error", err)
+	}
+}
@@ -5,11 +5,19 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/theluminousartemis/inkspire/internal/badges"
+	"github.com/theluminousartemis/inkspire/internal/notifications"
 	"github.com/theluminousartemis/inkspire/internal/store"
+	"github.com/theluminousartemis/inkspire/internal/timeline"
 )
 
 type userKey string
 
+type UserProfile struct {
+	*store.User
+	Badges []store.UserBadge `json:"badges"`
+}
+
 var userCtxKey userKey = "user"
 
 // GetUser godoc
@@ -20,7 +28,7 @@ var userCtxKey userKey = "user"
 //	@Accept			json
 //	@Produce		json
 //	@Param			id	path		int	true	"User ID"
-//	@Success		200	{object}	store.User
+//	@Success		200	{object}	UserProfile
 //	@Failure		400	{object}	error
 //	@Failure		404	{object}	error
 //	@Failure		500	{object}	error
@@ -45,7 +53,18 @@ func (app *application) getUserHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, user); err != nil {
+	userBadges, err := app.storage.Badges.GetByUserID(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	profile := UserProfile{
+		User:   user,
+		Badges: userBadges,
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, profile); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -94,6 +113,10 @@ func (app *application) followUserHandler(w http.ResponseWriter, r *http.Request
 		}
 
 	}
+	app.badges.Emit(badges.EventFollowed, followedID)
+	app.notify(ctx, followedID, notifications.TypeFollow, followuser.ID, nil, nil)
+	app.updateTimelines("follow", func(t *timeline.Service) error { return t.Follow(ctx, followuser.ID, followedID) })
+
 	if err := app.jsonResponse(w, http.StatusNoContent, nil); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -127,6 +150,7 @@ func (app *application) unfollowUserHandler(w http.ResponseWriter, r *http.Reque
 		app.internalServerError(w, r, err)
 		return
 	}
+	app.updateTimelines("unfollow", func(t *timeline.Service) error { return t.Unfollow(ctx, followuser.ID, unfollowedID) })
 
 	if err := app.jsonResponse(w, http.StatusNoContent, nil); err != nil {
 		app.internalServerError(w, r, err)
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/theluminousartemis/inkspire/internal/badges"
+	"github.com/theluminousartemis/inkspire/internal/notifications"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+type VotePayload struct {
+	Value *int `json:"value" validate:"required,oneof=-1 0 1"`
+}
+
+// VotePost godoc
+//
+//	@Summary		Votes on a post
+//	@Description	Upvotes (1), downvotes (-1) or removes the vote (0) of the authenticated user on a post
+//	@Tags			posts, votes
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID	path		int			true	"Post ID"
+//	@Param			payload	body		VotePayload	true	"Vote payload"
+//	@Success		200		{object}	store.Vote
+//	@Failure		400		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/vote [put]
+func (app *application) votePostHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	user := getUserFromCtx(r)
+
+	value, err := readVote(w, r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if post.UserID == user.ID {
+		app.badRequestError(w, r, errors.New("cannot vote on your own post"))
+		return
+	}
+
+	if !app.canVote(w, r, user, value) {
+		return
+	}
+
+	vote, err := app.storage.Votes.VotePost(r.Context(), post.ID, user.ID, value)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.postNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.invalidateUser(r.Context(), vote.AuthorID)
+	app.badges.Emit(badges.EventVoted, vote.AuthorID)
+	if vote.Value == 1 && vote.Previous != 1 {
+		app.notify(r.Context(), vote.AuthorID, notifications.TypePostVote, user.ID, &post.ID, nil)
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, vote); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// VoteComment godoc
+//
+//	@Summary		Votes on a comment
+//	@Description	Upvotes (1), downvotes (-1) or removes the vote (0) of the authenticated user on a comment
+//	@Tags			posts, comments, votes
+//	@Accept			json
+//	@Produce		json
+//	@Param			postID		path		int			true	"Post ID"
+//	@Param			commentID	path		int			true	"Comment ID"
+//	@Param			payload		body		VotePayload	true	"Vote payload"
+//	@Success		200			{object}	store.Vote
+//	@Failure		400			{object}	error
+//	@Failure		403			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{postID}/comments/{commentID}/vote [put]
+func (app *application) voteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	comment := getCommentfromCtx(r)
+	user := getUserFromCtx(r)
+
+	if comment.PostID != post.ID {
+		app.commentNotFoundErrorResponse(w, r, store.ErrNotFound)
+		return
+	}
+
+	value, err := readVote(w, r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if comment.UserID == user.ID {
+		app.badRequestError(w, r, errors.New("cannot vote on your own comment"))
+		return
+	}
+
+	if !app.canVote(w, r, user, value) {
+		return
+	}
+
+	vote, err := app.storage.Votes.VoteComment(r.Context(), comment.ID, user.ID, value)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.commentNotFoundErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.invalidateUser(r.Context(), vote.AuthorID)
+	app.badges.Emit(badges.EventVoted, vote.AuthorID)
+	if vote.Value == 1 && vote.Previous != 1 {
+		app.notify(r.Context(), vote.AuthorID, notifications.TypeCommentVote, user.ID, &post.ID, &comment.ID)
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, vote); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// canVote checks downvotes against the downvote privilege, moderators can always
+// downvote. It writes the error response when the vote is not allowed.
+func (app *application) canVote(w http.ResponseWriter, r *http.Request, user *store.User, value int) bool {
+	if value >= 0 || app.hasPrivilege(user, privilegeDownvote) {
+		return true
+	}
+
+	allowed, err := app.checkRolePrecedence(r.Context(), user, "moderator")
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	if !allowed {
+		app.l.Warnw("User lacks reputation to downvote", "userID", user.ID, "reputation", user.Reputation)
+		app.forbiddenResponse(w, r)
+		return false
+	}
+	return true
+}
+
+func readVote(w http.ResponseWriter, r *http.Request) (int, error) {
+	var payload VotePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		return 0, err
+	}
+	if err := validate.Struct(payload); err != nil {
+		return 0, err
+	}
+	return *payload.Value, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/theluminousartemis/inkspire/internal/notifications"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+func TestVotePost(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	votes := app.storage.Votes.(*store.MockVoteStore)
+	notified := app.storage.Notifications.(*store.MockNotificationStore)
+
+	steps := []struct {
+		name      string
+		body      string
+		wantScore int
+		notifies  int
+	}{
+		{"upvotes", `{"value": 1}`, 1, 1},
+		{"upvotes again", `{"value": 1}`, 1, 1},
+		{"changes to a downvote", `{"value": -1}`, -1, 1},
+		{"removes the vote", `{"value": 0}`, 0, 1},
+		{"upvotes after removing", `{"value": 1}`, 1, 2},
+	}
+	for _, step := range steps {
+		var vote store.Vote
+		readData(t, client.do(t, http.MethodPut, "/v1/posts/2/vote", step.body), http.StatusOK, &vote)
+		if vote.UserID != 1 || vote.Score != step.wantScore {
+			t.Errorf("%s: got %+v, want a score of %d by user 1", step.name, vote, step.wantScore)
+		}
+		if got := votes.Posts[2][1]; got != vote.Value {
+			t.Errorf("%s: stored vote %d, want %d", step.name, got, vote.Value)
+		}
+		if got := len(notified.Created); got != step.notifies {
+			t.Errorf("%s: got %d notifications, want %d", step.name, got, step.notifies)
+		}
+	}
+
+	n := notified.Created[0]
+	if n.UserID != 2 || n.Type != notifications.TypePostVote || *n.ActorID != 1 || *n.PostID != 2 {
+		t.Errorf("got notification %+v, want a post upvote of user 1 on post 2 for user 2", n)
+	}
+}
+
+func TestVotePostErrors(t *testing.T) {
+	app := newTestApplication(t, config{reputation: reputationConfig{downvote: 125}})
+	client := newTestClient(t, app)
+	votes := app.storage.Votes.(*store.MockVoteStore)
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{"refuses an unknown value", "/v1/posts/2/vote", `{"value": 2}`, http.StatusBadRequest},
+		{"refuses a missing value", "/v1/posts/2/vote", `{}`, http.StatusBadRequest},
+		{"refuses votes on own posts", "/v1/posts/1/vote", `{"value": 1}`, http.StatusBadRequest},
+		{"needs reputation to downvote", "/v1/posts/2/vote", `{"value": -1}`, http.StatusForbidden},
+		{"votes on a missing post", "/v1/posts/3/vote", `{"value": 1}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResponseCode(t, tt.want, client.do(t, http.MethodPut, tt.path, tt.body).Code)
+		})
+	}
+	if len(votes.Posts) != 0 {
+		t.Errorf("got votes %v, want none", votes.Posts)
+	}
+}
+
+func TestVoteComment(t *testing.T) {
+	app := newTestApplication(t, config{})
+	client := newTestClient(t, app)
+	votes := app.storage.Votes.(*store.MockVoteStore)
+	notified := app.storage.Notifications.(*store.MockNotificationStore)
+
+	var vote store.Vote
+	readData(t, client.do(t, http.MethodPut, "/v1/posts/1/comments/1/vote", `{"value": 1}`), http.StatusOK, &vote)
+	if vote.Value != 1 || vote.Score != 1 || votes.Comments[1][1] != 1 {
+		t.Errorf("got %+v and votes %v, want an upvote of user 1 on comment 1", vote, votes.Comments)
+	}
+	if len(notified.Created) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(notified.Created))
+	}
+	n := notified.Created[0]
+	if n.UserID != 2 || n.Type != notifications.TypeCommentVote || *n.CommentID != 1 {
+		t.Errorf("got notification %+v, want a comment upvote on comment 1 for user 2", n)
+	}
+
+	rr := client.do(t, http.MethodPut, "/v1/posts/2/comments/1/vote", `{"value": -1}`)
+	checkResponseCode(t, http.StatusNotFound, rr.Code)
+	if votes.Comments[1][1] != 1 {
+		t.Errorf("got vote %d through another post, want the upvote kept", votes.Comments[1][1])
+	}
+}
@@ -0,0 +1,165 @@
+package badges
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+	"go.uber.org/zap"
+)
+
+const (
+	EventPostCreated    = "post_created"
+	EventCommentCreated = "comment_created"
+	EventAnswerAccepted = "answer_accepted"
+	EventFollowed       = "followed"
+	EventVoted          = "voted"
+	EventBountyAwarded  = "bounty_awarded"
+)
+
+// eventMetrics lists the badge metrics that can change when an event happens
+// to a user, only badges based on those metrics are evaluated.
+var eventMetrics = map[string][]string{
+	EventPostCreated:    {store.BadgeMetricPosts, store.BadgeMetricStreakDays},
+	EventCommentCreated: {store.BadgeMetricComments, store.BadgeMetricStreakDays},
+	EventAnswerAccepted: {store.BadgeMetricAcceptedAnswers, store.BadgeMetricReputation},
+	EventFollowed:       {store.BadgeMetricFollowers},
+	EventVoted:          {store.BadgeMetricReputation},
+	EventBountyAwarded:  {store.BadgeMetricReputation},
+}
+
+// activityEvents count towards the user's daily activity streak.
+var activityEvents = map[string]bool{
+	EventPostCreated:    true,
+	EventCommentCreated: true,
+}
+
+type Event struct {
+	Type   string
+	UserID int64
+}
+
+// Engine evaluates badge rules stored in the database against events in the
+// background and awards the badges whose threshold is reached.
+type Engine struct {
+	store   store.Storage
+	l       *zap.SugaredLogger
+	events  chan Event
+	done    chan struct{}
+	workers int
+	wg      sync.WaitGroup
+}
+
+func NewEngine(store store.Storage, l *zap.SugaredLogger, workers, buffer int) *Engine {
+	return &Engine{
+		store:   store,
+		l:       l,
+		events:  make(chan Event, buffer),
+		done:    make(chan struct{}),
+		workers: workers,
+	}
+}
+
+func (e *Engine) Start() {
+	for i := 0; i < e.workers; i++ {
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			for {
+				select {
+				case event := <-e.events:
+					e.process(event)
+				case <-e.done:
+					e.drain()
+					return
+				}
+			}
+		}()
+	}
+}
+
+// drain processes the events still queued when the engine is stopped.
+func (e *Engine) drain() {
+	for {
+		select {
+		case event := <-e.events:
+			e.process(event)
+		default:
+			return
+		}
+	}
+}
+
+// Stop waits for the queued events to be processed, events emitted afterwards
+// are dropped.
+func (e *Engine) Stop() {
+	close(e.done)
+	e.wg.Wait()
+}
+
+// Emit queues the event without blocking the caller, events are dropped when
+// the queue is full.
+func (e *Engine) Emit(eventType string, userID int64) {
+	select {
+	case <-e.done:
+		e.l.Warnw("badge engine stopped, dropping event", "type", eventType, "userID", userID)
+		return
+	default:
+	}
+
+	select {
+	case e.events <- Event{Type: eventType, UserID: userID}:
+	default:
+		e.l.Warnw("badge event queue full, dropping event", "type", eventType, "userID", userID)
+	}
+}
+
+func (e *Engine) process(event Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if activityEvents[event.Type] {
+		if err := e.store.Badges.RecordActivity(ctx, event.UserID); err != nil {
+			e.l.Errorw("error recording user activity", "userID", event.UserID, "error", err)
+		}
+	}
+
+	metrics, ok := eventMetrics[event.Type]
+	if !ok {
+		e.l.Warnw("unknown badge event", "type", event.Type)
+		return
+	}
+
+	badges, err := e.store.Badges.GetUnawarded(ctx, event.UserID, metrics)
+	if err != nil {
+		e.l.Errorw("error fetching badges", "userID", event.UserID, "error", err)
+		return
+	}
+
+	values := make(map[string]int)
+	for _, badge := range badges {
+		value, ok := values[badge.Metric]
+		if !ok {
+			value, err = e.store.Badges.Metric(ctx, event.UserID, badge.Metric)
+			if err != nil {
+				e.l.Errorw("error computing badge metric", "userID", event.UserID, "metric", badge.Metric, "error", err)
+				continue
+			}
+			values[badge.Metric] = value
+		}
+
+		if value < badge.Threshold {
+			continue
+		}
+
+		awarded, err := e.store.Badges.Award(ctx, event.UserID, badge.ID)
+		if err != nil {
+			e.l.Errorw("error awarding badge", "userID", event.UserID, "badge", badge.Name, "error", err)
+			continue
+		}
+		if awarded {
+			e.l.Infow("badge awarded", "userID", event.UserID, "badge", badge.Name)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package env
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func GetString(key, fallback string) string {
@@ -36,3 +38,44 @@ func GetBool(key string, fallback bool) bool {
 	}
 	return boolVal
 }
+
+func GetFloat(key string, fallback float64) float64 {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	floatVal, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return fallback
+	}
+	return floatVal
+}
+
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	durationVal, err := time.ParseDuration(val)
+	if err != nil {
+		return fallback
+	}
+	return durationVal
+}
+
+func GetList(key string, fallback []string) []string {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return fallback
+	}
+	return list
+}
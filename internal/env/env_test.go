@@ -0,0 +1,32 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetList(t *testing.T) {
+	fallback := []string{"thumbs_up", "heart"}
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"single entry", "heart", []string{"heart"}},
+		{"trims entries", " heart, rocket ,eyes", []string{"heart", "rocket", "eyes"}},
+		{"skips empty entries", "heart,, ,rocket,", []string{"heart", "rocket"}},
+		{"falls back when empty", " , ", fallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_LIST", tt.value)
+			if got := GetList("TEST_LIST", fallback); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetList(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	if got := GetList("TEST_LIST_UNSET", fallback); !reflect.DeepEqual(got, fallback) {
+		t.Errorf("got %q for an unset variable, want %q", got, fallback)
+	}
+}
@@ -3,9 +3,11 @@ package mailer
 import "embed"
 
 const (
-	FromName            = "wise.ly"
-	maxRetries          = 3
-	UserWelcomeTemplate = "user_invitations.tmpl"
+	FromName               = "wise.ly"
+	maxRetries             = 3
+	UserWelcomeTemplate    = "user_invitations.tmpl"
+	AnswerAcceptedTemplate = "answer_accepted.tmpl"
+	MentionTemplate        = "mention.tmpl"
 )
 
 //go:embed "templates"
@@ -0,0 +1,7 @@
+package mailer
+
+type MockClient struct{}
+
+func (m *MockClient) Send(templateFile, username, email string, data any, isProdEnv bool) (int, error) {
+	return 200, nil
+}
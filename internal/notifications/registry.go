@@ -0,0 +1,72 @@
+package notifications
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+const (
+	TypeComment     = "comment"
+	TypeReply       = "reply"
+	TypeFollow      = "follow"
+	TypePostVote    = "post_upvote"
+	TypeCommentVote = "comment_upvote"
+	TypeMention     = "mention"
+)
+
+// Type is a kind of notification. Message describes a notification of the
+// type to its recipient, actor is the username of the user who caused it.
+type Type struct {
+	Name    string
+	Message func(actor string) string
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Type{}
+)
+
+func init() {
+	Register(Type{Name: TypeComment, Message: func(actor string) string { return actor + " commented on your post" }})
+	Register(Type{Name: TypeReply, Message: func(actor string) string { return actor + " replied to your comment" }})
+	Register(Type{Name: TypeFollow, Message: func(actor string) string { return actor + " started following you" }})
+	Register(Type{Name: TypePostVote, Message: func(actor string) string { return actor + " upvoted your post" }})
+	Register(Type{Name: TypeCommentVote, Message: func(actor string) string { return actor + " upvoted your comment" }})
+	Register(Type{Name: TypeMention, Message: func(actor string) string { return actor + " mentioned you" }})
+}
+
+// Register adds a type of notification, registering a name twice panics.
+func Register(t Type) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := registry[t.Name]; ok {
+		panic(fmt.Sprintf("notifications: type %q registered twice", t.Name))
+	}
+	registry[t.Name] = t
+}
+
+// Lookup returns the registered type of the name.
+func Lookup(name string) (Type, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	t, ok := registry[name]
+	return t, ok
+}
+
+// Describe sets the message of the notifications from their type, the
+// notifications of unknown types keep an empty message.
+func Describe(ns []store.Notification) {
+	for i := range ns {
+		t, ok := Lookup(ns[i].Type)
+		if !ok {
+			continue
+		}
+		actor := ns[i].ActorUsername
+		if actor == "" {
+			actor = "Someone"
+		}
+		ns[i].Message = t.Message(actor)
+	}
+}
@@ -0,0 +1,73 @@
+package ranking
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+)
+
+// Weights scales each signal of the feed ranking. The counts are dampened with
+// a logarithm so a handful of interactions matter more than piling them up.
+type Weights struct {
+	Recency  float64
+	Affinity float64
+	Votes    float64
+	Comments float64
+	// HalfLife is the age at which the recency of a post is halved, zero
+	// disables the decay
+	HalfLife time.Duration
+}
+
+// Candidate holds the signals of a post considered for the feed. Affinity is
+// the number of times the reader interacted with the author.
+type Candidate struct {
+	ID        int64
+	CreatedAt time.Time
+	Votes     int
+	Comments  int
+	Affinity  int
+}
+
+// Score rates the candidate at time now, higher is better.
+func (w Weights) Score(c Candidate, now time.Time) float64 {
+	recency := 1.0
+	if w.HalfLife > 0 {
+		age := max(now.Sub(c.CreatedAt), 0)
+		recency = math.Exp2(-float64(age) / float64(w.HalfLife))
+	}
+
+	return w.Recency*recency +
+		w.Affinity*dampen(c.Affinity) +
+		w.Votes*dampen(c.Votes) +
+		w.Comments*dampen(c.Comments)
+}
+
+// Rank orders the candidates by descending score, newest first on ties. The
+// candidates are left untouched.
+func (w Weights) Rank(candidates []Candidate, now time.Time) []Candidate {
+	scores := make(map[int64]float64, len(candidates))
+	for _, c := range candidates {
+		scores[c.ID] = w.Score(c, now)
+	}
+
+	ranked := slices.Clone(candidates)
+	slices.SortStableFunc(ranked, func(a, b Candidate) int {
+		if c := cmp.Compare(scores[b.ID], scores[a.ID]); c != 0 {
+			return c
+		}
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return ranked
+}
+
+// dampen is a signed log1p, so negative vote scores rank below zero.
+func dampen(n int) float64 {
+	if n < 0 {
+		return -math.Log1p(float64(-n))
+	}
+	return math.Log1p(float64(n))
+}
@@ -0,0 +1,82 @@
+package ranking
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	w := Weights{Recency: 1, HalfLife: 24 * time.Hour}
+
+	t.Run("recency halves every half-life", func(t *testing.T) {
+		cases := []struct {
+			age  time.Duration
+			want float64
+		}{
+			{0, 1},
+			{24 * time.Hour, 0.5},
+			{48 * time.Hour, 0.25},
+			{-time.Hour, 1},
+		}
+		for _, tc := range cases {
+			got := w.Score(Candidate{CreatedAt: now.Add(-tc.age)}, now)
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("age %v: got %v, want %v", tc.age, got, tc.want)
+			}
+		}
+	})
+
+	t.Run("zero half-life disables the decay", func(t *testing.T) {
+		w := Weights{Recency: 2}
+		if got := w.Score(Candidate{CreatedAt: now.AddDate(-1, 0, 0)}, now); got != 2 {
+			t.Errorf("got %v, want 2", got)
+		}
+	})
+
+	t.Run("counts are dampened and signed", func(t *testing.T) {
+		w := Weights{Votes: 1}
+		up := w.Score(Candidate{Votes: 10, CreatedAt: now}, now)
+		down := w.Score(Candidate{Votes: -10, CreatedAt: now}, now)
+		if up <= 0 || down != -up {
+			t.Errorf("got %v and %v, want opposite scores", up, down)
+		}
+		if more := w.Score(Candidate{Votes: 20, CreatedAt: now}, now); more >= 2*up {
+			t.Errorf("doubling the votes doubled the score: %v and %v", up, more)
+		}
+	})
+}
+
+func TestRank(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	w := Weights{Recency: 1, Affinity: 1, Votes: 0.5, Comments: 0.5, HalfLife: 24 * time.Hour}
+
+	candidates := []Candidate{
+		{ID: 1, CreatedAt: now.Add(-time.Hour)},
+		{ID: 2, CreatedAt: now.Add(-72 * time.Hour), Affinity: 20},
+		{ID: 3, CreatedAt: now.Add(-2 * time.Hour), Votes: 5, Comments: 3},
+		{ID: 4, CreatedAt: now.Add(-time.Hour)},
+		{ID: 5, CreatedAt: now.Add(-time.Hour)},
+	}
+
+	ranked := w.Rank(candidates, now)
+
+	want := []int64{2, 3, 5, 4, 1}
+	for i, c := range ranked {
+		if c.ID != want[i] {
+			t.Fatalf("got %v, want ids %v", ids(ranked), want)
+		}
+	}
+	if candidates[0].ID != 1 {
+		t.Error("Rank reordered its input")
+	}
+}
+
+func ids(candidates []Candidate) []int64 {
+	ids := make([]int64, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
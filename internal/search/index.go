@@ -0,0 +1,403 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/token/porter"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/regexp"
+	"github.com/blevesearch/bleve/v2/mapping"
+	htmlhighlighter "github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+const (
+	fieldTitle     = "title"
+	fieldTags      = "tags"
+	fieldContent   = "content"
+	fieldComments  = "comments"
+	fieldUsername  = "username"
+	fieldCreatedAt = "created_at"
+
+	postAnalyzer = "post"
+	postWords    = "post_words"
+
+	rebuildBatch = 500
+)
+
+// textWeights ranks matches in the title above the content and then the
+// comments, like the weights of the Postgres search vector. Tags weigh 3.
+var textWeights = map[string]float64{fieldTitle: 4, fieldContent: 1.5, fieldComments: 1}
+
+const (
+	tagWeight = 3
+	// fuzzyWeight scales the score of terms matched with typos
+	fuzzyWeight = 0.5
+	// fuzzyPrefix is the number of leading characters a term matched with
+	// typos shares with the query term, it bounds the expansion of fuzzy terms
+	fuzzyPrefix = 1
+)
+
+// document is the indexed form of a post, only the title and the content are
+// stored to be highlighted.
+type document struct {
+	Title     string    `json:"title"`
+	Tags      []string  `json:"tags"`
+	Content   string    `json:"content"`
+	Comments  []string  `json:"comments"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newDocument(d store.SearchDocument) document {
+	return document{
+		Title:     d.Title,
+		Tags:      d.Tags,
+		Content:   d.Content,
+		Comments:  d.Comments,
+		Username:  d.User.Username,
+		CreatedAt: d.CreatedAt,
+	}
+}
+
+func docID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// newMapping analyzes the text fields into lower-cased stemmed words without
+// stopwords, keeping '+' and '#' in words so terms such as c++ and c# survive.
+func newMapping() (*mapping.IndexMappingImpl, error) {
+	im := bleve.NewIndexMapping()
+	err := im.AddCustomTokenizer(postWords, map[string]interface{}{
+		"type":   regexp.Name,
+		"regexp": `[\p{L}\p{N}][\p{L}\p{N}+#]*`,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = im.AddCustomAnalyzer(postAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     postWords,
+		"token_filters": []string{lowercase.Name, en.StopName, porter.Name},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	text := func(store bool) *mapping.FieldMapping {
+		f := bleve.NewTextFieldMapping()
+		f.Analyzer = postAnalyzer
+		f.Store = store
+		f.IncludeTermVectors = store
+		return f
+	}
+	kw := bleve.NewKeywordFieldMapping()
+	kw.Analyzer = keyword.Name
+	kw.Store = false
+
+	created := bleve.NewDateTimeFieldMapping()
+	created.Store = false
+
+	doc := bleve.NewDocumentStaticMapping()
+	doc.AddFieldMappingsAt(fieldTitle, text(true))
+	doc.AddFieldMappingsAt(fieldContent, text(true))
+	doc.AddFieldMappingsAt(fieldComments, text(false))
+	doc.AddFieldMappingsAt(fieldTags, kw)
+	doc.AddFieldMappingsAt(fieldUsername, kw)
+	doc.AddFieldMappingsAt(fieldCreatedAt, created)
+
+	im.DefaultMapping = doc
+	im.DefaultAnalyzer = postAnalyzer
+	return im, nil
+}
+
+// Index is an embedded inverted index persisted on disk with Bleve. It
+// tolerates typos in the query terms.
+//
+// Only one process may open the index at a time.
+type Index struct {
+	index  bleve.Index
+	source Source
+}
+
+// NewIndex opens the index stored in dir, creating it if dir is missing or
+// empty. A new index is empty until it is rebuilt.
+func NewIndex(dir string, source Source) (*Index, error) {
+	index, err := bleve.Open(dir)
+	if errors.Is(err, bleve.ErrorIndexMetaMissing) {
+		if entries, rerr := os.ReadDir(dir); rerr == nil && len(entries) == 0 {
+			err = os.Remove(dir)
+			if err == nil {
+				err = bleve.ErrorIndexPathDoesNotExist
+			}
+		}
+	}
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		var im *mapping.IndexMappingImpl
+		im, err = newMapping()
+		if err != nil {
+			return nil, err
+		}
+		index, err = bleve.New(dir, im)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Index{index: index, source: source}, nil
+}
+
+// Index reindexes the posts from the source, posts that no longer exist or were
+// deleted are removed.
+func (idx *Index) Index(ctx context.Context, postIDs ...int64) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	docs, err := idx.source.GetDocuments(ctx, postIDs)
+	if err != nil {
+		return err
+	}
+
+	batch := idx.index.NewBatch()
+	found := make(map[int64]bool, len(docs))
+	for _, d := range docs {
+		found[d.ID] = true
+		if err := batch.Index(docID(d.ID), newDocument(d)); err != nil {
+			return err
+		}
+	}
+	for _, id := range postIDs {
+		if !found[id] {
+			batch.Delete(docID(id))
+		}
+	}
+	return idx.index.Batch(batch)
+}
+
+func (idx *Index) Remove(ctx context.Context, postIDs ...int64) error {
+	batch := idx.index.NewBatch()
+	for _, id := range postIDs {
+		batch.Delete(docID(id))
+	}
+	return idx.index.Batch(batch)
+}
+
+// Rebuild indexes every live post of the source and removes the documents of
+// the posts that are gone.
+func (idx *Index) Rebuild(ctx context.Context) (int, error) {
+	live := make(map[string]bool)
+	var afterID int64
+	for {
+		docs, err := idx.source.ListDocuments(ctx, afterID, rebuildBatch)
+		if err != nil {
+			return 0, err
+		}
+		batch := idx.index.NewBatch()
+		for _, d := range docs {
+			live[docID(d.ID)] = true
+			if err := batch.Index(docID(d.ID), newDocument(d)); err != nil {
+				return 0, err
+			}
+			afterID = d.ID
+		}
+		if err := idx.index.Batch(batch); err != nil {
+			return 0, err
+		}
+		if len(docs) < rebuildBatch {
+			break
+		}
+	}
+
+	var stale []string
+	req := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), rebuildBatch, 0, false)
+	req.SortBy([]string{"_id"})
+	for {
+		res, err := idx.index.SearchInContext(ctx, req)
+		if err != nil {
+			return 0, err
+		}
+		for _, hit := range res.Hits {
+			if !live[hit.ID] {
+				stale = append(stale, hit.ID)
+			}
+		}
+		if len(res.Hits) < rebuildBatch {
+			break
+		}
+		req.SetSearchAfter([]string{res.Hits[len(res.Hits)-1].ID})
+	}
+
+	batch := idx.index.NewBatch()
+	for _, id := range stale {
+		batch.Delete(id)
+	}
+	if err := idx.index.Batch(batch); err != nil {
+		return 0, err
+	}
+	return len(live), nil
+}
+
+func (idx *Index) Close() error {
+	return idx.index.Close()
+}
+
+// Search ranks the posts matching every word of the query. Words match the
+// indexed terms within a couple of typos at a lower score, words prefixed with
+// '-' exclude the posts containing them.
+func (idx *Index) Search(ctx context.Context, sq store.SearchQuery) ([]store.SearchResult, error) {
+	q, ok := idx.query(sq)
+	if !ok {
+		return []store.SearchResult{}, nil
+	}
+
+	req := bleve.NewSearchRequestOptions(q, sq.Limit, sq.Offset, false)
+	req.SortBy([]string{"-_score", "-" + fieldCreatedAt})
+	req.Highlight = bleve.NewHighlightWithStyle(htmlhighlighter.Name)
+	req.Highlight.AddField(fieldTitle)
+	req.Highlight.AddField(fieldContent)
+
+	res, err := idx.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		id, err := strconv.ParseInt(hit.ID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	docs, err := idx.source.GetDocuments(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]store.SearchDocument, len(docs))
+	for _, d := range docs {
+		byID[docID(d.ID)] = d
+	}
+
+	results := make([]store.SearchResult, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		d, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		r := store.SearchResult{
+			ID:           d.ID,
+			Title:        d.Title,
+			Tags:         d.Tags,
+			Score:        d.Score,
+			CommentCount: len(d.Comments),
+			CreatedAt:    d.CreatedAt,
+			User:         d.User,
+			Highlight:    html.EscapeString(d.Title),
+			Rank:         hit.Score,
+		}
+		if f := hit.Fragments[fieldTitle]; len(f) > 0 {
+			r.Highlight = f[0]
+		}
+		if f := hit.Fragments[fieldContent]; len(f) > 0 {
+			r.Snippet = f[0]
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// query builds the Bleve query of the search, ok is false when the search
+// has no word to match.
+func (idx *Index) query(sq store.SearchQuery) (query.Query, bool) {
+	analyzer := idx.index.Mapping().AnalyzerNamed(postAnalyzer)
+	q := bleve.NewBooleanQuery()
+
+	var words int
+	for _, word := range strings.Fields(sq.Query) {
+		negated, exclude := strings.CutPrefix(word, "-")
+		if exclude {
+			word = negated
+		}
+		tag := bleve.NewTermQuery(strings.ToLower(word))
+		tag.SetField(fieldTags)
+
+		for _, token := range analyzer.Analyze([]byte(word)) {
+			term := string(token.Term)
+			if exclude {
+				for field := range textWeights {
+					tq := bleve.NewTermQuery(term)
+					tq.SetField(field)
+					q.AddMustNot(tq)
+				}
+				continue
+			}
+			q.AddMust(termQuery(term, tag))
+			words++
+		}
+		if exclude {
+			q.AddMustNot(tag)
+		}
+	}
+	if words == 0 {
+		return nil, false
+	}
+
+	for _, t := range sq.Tags {
+		tq := bleve.NewTermQuery(t)
+		tq.SetField(fieldTags)
+		q.AddMust(tq)
+	}
+	if sq.Author != "" {
+		tq := bleve.NewTermQuery(sq.Author)
+		tq.SetField(fieldUsername)
+		q.AddMust(tq)
+	}
+	if sq.Since != nil || sq.Until != nil {
+		var since, until time.Time
+		if sq.Since != nil {
+			since = *sq.Since
+		}
+		if sq.Until != nil {
+			until = *sq.Until
+		}
+		inclusive, exclusive := true, false
+		dq := bleve.NewDateRangeInclusiveQuery(since, until, &inclusive, &exclusive)
+		dq.SetField(fieldCreatedAt)
+		q.AddMust(dq)
+	}
+	return q, true
+}
+
+// termQuery matches the analyzed term in any text field, exactly or within
+// maxEdits typos at a lower score, or the word as a tag.
+func termQuery(term string, tag *query.TermQuery) query.Query {
+	tag.SetBoost(tagWeight)
+	clauses := []query.Query{tag}
+	edits := maxEdits(term)
+	for field, weight := range textWeights {
+		exact := bleve.NewTermQuery(term)
+		exact.SetField(field)
+		exact.SetBoost(weight)
+		clauses = append(clauses, exact)
+
+		if edits > 0 {
+			fuzzy := bleve.NewFuzzyQuery(term)
+			fuzzy.SetField(field)
+			fuzzy.SetFuzziness(edits)
+			fuzzy.SetPrefix(fuzzyPrefix)
+			fuzzy.SetBoost(weight * fuzzyWeight)
+			clauses = append(clauses, fuzzy)
+		}
+	}
+	return bleve.NewDisjunctionQuery(clauses...)
+}
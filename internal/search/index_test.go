@@ -0,0 +1,230 @@
+package search
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+// fakeSource serves the documents of its map, sorted by ID when listed.
+type fakeSource map[int64]store.SearchDocument
+
+func (s fakeSource) GetDocuments(ctx context.Context, ids []int64) ([]store.SearchDocument, error) {
+	var docs []store.SearchDocument
+	for _, id := range ids {
+		if d, ok := s[id]; ok {
+			docs = append(docs, d)
+		}
+	}
+	return docs, nil
+}
+
+func (s fakeSource) ListDocuments(ctx context.Context, afterID int64, limit int) ([]store.SearchDocument, error) {
+	var ids []int64
+	for id := range s {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	docs := []store.SearchDocument{}
+	for _, id := range ids[:min(limit, len(ids))] {
+		docs = append(docs, s[id])
+	}
+	return docs, nil
+}
+
+var now = time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestSource() fakeSource {
+	return fakeSource{
+		1: {
+			ID: 1, Title: "Tuning postgres indexes", Tags: []string{"postgres"},
+			Content: "Partial indexes keep the writes cheap.", Comments: []string{"What about redis?"},
+			CreatedAt: now.Add(-48 * time.Hour), User: store.PostUser{ID: 1, Username: "alice"},
+		},
+		2: {
+			ID: 2, Title: "Caching with redis", Tags: []string{"redis", "cache"},
+			Content:   "Expire keys to bound the memory.",
+			CreatedAt: now.Add(-24 * time.Hour), User: store.PostUser{ID: 2, Username: "bob"},
+		},
+		3: {
+			ID: 3, Title: "<script>alert(1)</script> in c++ templates", Tags: []string{"c++"},
+			Content:   "Escape <b>everything</b> rendered from c++ programs.",
+			CreatedAt: now, User: store.PostUser{ID: 1, Username: "alice"},
+		},
+	}
+}
+
+func openTestIndex(t *testing.T, dir string, source Source) *Index {
+	t.Helper()
+	idx, err := NewIndex(dir, source)
+	if err != nil {
+		t.Fatalf("opening index: %v", err)
+	}
+	return idx
+}
+
+func searchIDs(t *testing.T, idx *Index, sq store.SearchQuery) []int64 {
+	t.Helper()
+	if sq.Limit == 0 {
+		sq.Limit = 10
+	}
+	results, err := idx.Search(context.Background(), sq)
+	if err != nil {
+		t.Fatalf("searching %q: %v", sq.Query, err)
+	}
+	ids := []int64{}
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestIndexSearch(t *testing.T) {
+	source := newTestSource()
+	idx := openTestIndex(t, t.TempDir(), source)
+	defer idx.Close()
+	if _, err := idx.Rebuild(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	since := now.Add(-36 * time.Hour)
+	tests := []struct {
+		name string
+		sq   store.SearchQuery
+		want []int64
+	}{
+		{"title match ranks first", store.SearchQuery{Query: "redis"}, []int64{2, 1}},
+		{"stemmed term", store.SearchQuery{Query: "index"}, []int64{1}},
+		{"typo within edits", store.SearchQuery{Query: "postgrse"}, []int64{1}},
+		{"short terms match exactly", store.SearchQuery{Query: "rds"}, []int64{}},
+		{"every word must match", store.SearchQuery{Query: "redis memory"}, []int64{2}},
+		{"excluded word", store.SearchQuery{Query: "redis -postgres"}, []int64{2}},
+		{"symbols kept in words", store.SearchQuery{Query: "c++"}, []int64{3}},
+		{"stopwords only", store.SearchQuery{Query: "the what"}, []int64{}},
+		{"tag filter", store.SearchQuery{Query: "redis", Tags: []string{"cache"}}, []int64{2}},
+		{"author filter", store.SearchQuery{Query: "redis", Author: "alice"}, []int64{1}},
+		{"since filter", store.SearchQuery{Query: "redis", Since: &since}, []int64{2}},
+		{"offset", store.SearchQuery{Query: "redis", Offset: 1}, []int64{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchIDs(t, idx, tt.sq); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexHighlightEscapesHTML(t *testing.T) {
+	idx := openTestIndex(t, t.TempDir(), newTestSource())
+	defer idx.Close()
+	if err := idx.Index(context.Background(), 1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := idx.Search(context.Background(), store.SearchQuery{Query: "templates", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	r := results[0]
+	if strings.Contains(r.Highlight, "<script>") || !strings.Contains(r.Highlight, "&lt;script&gt;") {
+		t.Errorf("title highlight not escaped: %q", r.Highlight)
+	}
+	if !strings.Contains(r.Highlight, "<mark>templates</mark>") {
+		t.Errorf("title highlight missing the match: %q", r.Highlight)
+	}
+	if strings.Contains(r.Snippet, "<mark>") {
+		t.Errorf("got marks in %q for a content without match", r.Snippet)
+	}
+
+	results, err = idx.Search(context.Background(), store.SearchQuery{Query: "everything", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || strings.Contains(results[0].Snippet, "<b>") {
+		t.Errorf("content snippet not escaped: %+v", results)
+	}
+}
+
+func TestIndexUpdatesAndPersists(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	source := newTestSource()
+	idx := openTestIndex(t, dir, source)
+	if err := idx.Index(ctx, 1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := idx.Remove(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchIDs(t, idx, store.SearchQuery{Query: "redis"}); !slices.Equal(got, []int64{1}) {
+		t.Errorf("after remove got %v, want [1]", got)
+	}
+
+	//indexing a post gone from the source removes it
+	delete(source, 1)
+	if err := idx.Index(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchIDs(t, idx, store.SearchQuery{Query: "postgres"}); len(got) != 0 {
+		t.Errorf("after indexing a missing post got %v, want none", got)
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	idx = openTestIndex(t, dir, source)
+	defer idx.Close()
+	if got := searchIDs(t, idx, store.SearchQuery{Query: "c++"}); !slices.Equal(got, []int64{3}) {
+		t.Errorf("after reopening got %v, want [3]", got)
+	}
+}
+
+func TestIndexRebuildDropsStaleDocuments(t *testing.T) {
+	ctx := context.Background()
+	source := newTestSource()
+	idx := openTestIndex(t, t.TempDir(), source)
+	defer idx.Close()
+	if err := idx.Index(ctx, 1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	delete(source, 2)
+	count, err := idx.Rebuild(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("rebuild indexed %d posts, want 2", count)
+	}
+	if got := searchIDs(t, idx, store.SearchQuery{Query: "redis"}); !slices.Equal(got, []int64{1}) {
+		t.Errorf("after rebuild got %v, want [1]", got)
+	}
+}
+
+func TestMaxEdits(t *testing.T) {
+	tests := []struct {
+		term string
+		want int
+	}{
+		{"go", 0},
+		{"rdis", 1},
+		{"caché", 1},
+		{"postgres", 2},
+	}
+	for _, tt := range tests {
+		if got := maxEdits(tt.term); got != tt.want {
+			t.Errorf("maxEdits(%q) = %d, want %d", tt.term, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"context"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+// Searcher is a search backend. Index and Remove are called whenever a post or
+// its comments change so backends keeping their own index stay in sync.
+type Searcher interface {
+	Search(context.Context, store.SearchQuery) ([]store.SearchResult, error)
+	Index(ctx context.Context, postIDs ...int64) error
+	Remove(ctx context.Context, postIDs ...int64) error
+	Close() error
+}
+
+// Source provides the documents to index, it is implemented by the search
+// store.
+type Source interface {
+	GetDocuments(context.Context, []int64) ([]store.SearchDocument, error)
+	ListDocuments(ctx context.Context, afterID int64, limit int) ([]store.SearchDocument, error)
+}
+
+// Postgres searches with the full-text search of the database, whose search
+// vectors are kept up to date by triggers.
+type Postgres struct {
+	store store.Storage
+}
+
+func NewPostgres(store store.Storage) *Postgres {
+	return &Postgres{store: store}
+}
+
+func (p *Postgres) Search(ctx context.Context, sq store.SearchQuery) ([]store.SearchResult, error) {
+	return p.store.Search.Search(ctx, sq)
+}
+
+func (p *Postgres) Index(ctx context.Context, postIDs ...int64) error {
+	return nil
+}
+
+func (p *Postgres) Remove(ctx context.Context, postIDs ...int64) error {
+	return nil
+}
+
+func (p *Postgres) Close() error {
+	return nil
+}
@@ -0,0 +1,14 @@
+package search
+
+// maxEdits is the number of typos tolerated in a query term, short terms have
+// to match exactly.
+func maxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
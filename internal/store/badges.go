@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	BadgeMetricPosts           = "posts"
+	BadgeMetricComments        = "comments"
+	BadgeMetricAcceptedAnswers = "accepted_answers"
+	BadgeMetricFollowers       = "followers"
+	BadgeMetricReputation      = "reputation"
+	BadgeMetricStreakDays      = "streak_days"
+)
+
+// badgeMetricQueries computes the current value of each badge metric for a user.
+var badgeMetricQueries = map[string]string{
+	BadgeMetricPosts:           `SELECT COUNT(*) FROM posts WHERE user_id = $1 AND deleted = false`,
+	BadgeMetricComments:        `SELECT COUNT(*) FROM comments WHERE user_id = $1 AND deleted = false`,
+	BadgeMetricAcceptedAnswers: `SELECT COUNT(*) FROM posts p JOIN comments c ON c.id = p.accepted_comment_id WHERE c.user_id = $1`,
+	BadgeMetricFollowers:       `SELECT COUNT(*) FROM followers WHERE user_id = $1`,
+	BadgeMetricReputation:      `SELECT reputation FROM users WHERE id = $1`,
+	BadgeMetricStreakDays: `
+	WITH days AS (
+	    SELECT day - (ROW_NUMBER() OVER (ORDER BY day))::int AS grp
+	    FROM user_activity
+	    WHERE user_id = $1
+	)
+	SELECT COALESCE(MAX(streak), 0) FROM (SELECT COUNT(*) AS streak FROM days GROUP BY grp) s`,
+}
+
+type Badge struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Metric      string    `json:"metric"`
+	Threshold   int       `json:"threshold"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type UserBadge struct {
+	Badge
+	AwardedAt time.Time `json:"awarded_at"`
+}
+
+type PostgresBadgeStore struct {
+	db *sql.DB
+}
+
+func (s *PostgresBadgeStore) Create(ctx context.Context, badge *Badge) error {
+	query := `INSERT INTO badges (name, description, metric, threshold) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	err := s.db.QueryRowContext(ctx, query, badge.Name, badge.Description, badge.Metric, badge.Threshold).Scan(&badge.ID, &badge.CreatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *PostgresBadgeStore) GetAll(ctx context.Context) ([]Badge, error) {
+	query := `SELECT id, name, description, metric, threshold, created_at FROM badges ORDER BY id`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	badges := []Badge{}
+	for rows.Next() {
+		var b Badge
+		if err := rows.Scan(&b.ID, &b.Name, &b.Description, &b.Metric, &b.Threshold, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		badges = append(badges, b)
+	}
+	return badges, rows.Err()
+}
+
+func (s *PostgresBadgeStore) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM badges WHERE id = $1`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetUnawarded returns the badges based on one of the metrics that the user
+// has not been awarded yet.
+func (s *PostgresBadgeStore) GetUnawarded(ctx context.Context, userID int64, metrics []string) ([]Badge, error) {
+	query := `
+	SELECT b.id, b.name, b.description, b.metric, b.threshold, b.created_at
+	FROM badges b
+	WHERE b.metric = ANY($2)
+	AND NOT EXISTS (SELECT 1 FROM user_badges ub WHERE ub.badge_id = b.id AND ub.user_id = $1)
+	ORDER BY b.threshold`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query, userID, pq.Array(metrics))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var badges []Badge
+	for rows.Next() {
+		var b Badge
+		if err := rows.Scan(&b.ID, &b.Name, &b.Description, &b.Metric, &b.Threshold, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		badges = append(badges, b)
+	}
+	return badges, rows.Err()
+}
+
+func (s *PostgresBadgeStore) GetByUserID(ctx context.Context, userID int64) ([]UserBadge, error) {
+	query := `
+	SELECT b.id, b.name, b.description, b.metric, b.threshold, b.created_at, ub.awarded_at
+	FROM user_badges ub
+	JOIN badges b ON b.id = ub.badge_id
+	WHERE ub.user_id = $1
+	ORDER BY ub.awarded_at`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	badges := []UserBadge{}
+	for rows.Next() {
+		var b UserBadge
+		if err := rows.Scan(&b.ID, &b.Name, &b.Description, &b.Metric, &b.Threshold, &b.CreatedAt, &b.AwardedAt); err != nil {
+			return nil, err
+		}
+		badges = append(badges, b)
+	}
+	return badges, rows.Err()
+}
+
+// Award gives the badge to the user, it reports false if the user already had it.
+func (s *PostgresBadgeStore) Award(ctx context.Context, userID, badgeID int64) (bool, error) {
+	query := `INSERT INTO user_badges (user_id, badge_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, query, userID, badgeID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (s *PostgresBadgeStore) Metric(ctx context.Context, userID int64, metric string) (int, error) {
+	query, ok := badgeMetricQueries[metric]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	var value int
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&value); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// RecordActivity marks the current day as active for the user's streak.
+func (s *PostgresBadgeStore) RecordActivity(ctx context.Context, userID int64) error {
+	query := `INSERT INTO user_activity (user_id, day) VALUES ($1, CURRENT_DATE) ON CONFLICT DO NOTHING`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := s.db.ExecContext(ctx, query, userID)
+	return err
+}
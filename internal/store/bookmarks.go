@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type Bookmark struct {
+	ID         int64          `json:"id"`
+	UserID     int64          `json:"user_id"`
+	PostID     int64          `json:"post_id"`
+	Collection *string        `json:"collection,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Post       BookmarkedPost `json:"post"`
+}
+
+type BookmarkedPost struct {
+	Title     string    `json:"title"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	User      PostUser  `json:"user"`
+}
+
+type BookmarkCollection struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Count     int       `json:"count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BookmarkQuery struct {
+	Limit      int    `json:"limit" validate:"gte=1,lte=50"`
+	Cursor     string `json:"cursor"`
+	Collection string `json:"collection" validate:"max=100"`
+}
+
+func (bq BookmarkQuery) Parse(r *http.Request) (BookmarkQuery, error) {
+	q := r.URL.Query()
+	limit := q.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return bq, err
+		}
+		bq.Limit = l
+	}
+
+	bq.Cursor = q.Get("cursor")
+	bq.Collection = q.Get("collection")
+	return bq, nil
+}
+
+type PostgresBookmarkStore struct {
+	db *sql.DB
+}
+
+// Add bookmarks the post, creating the named collection if needed. Bookmarking
+// an already bookmarked post moves it to the given collection.
+func (s *PostgresBookmarkStore) Add(ctx context.Context, bookmark *Bookmark) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var collectionID *int64
+		if bookmark.Collection != nil {
+			query := `INSERT INTO bookmark_collections (user_id, name) VALUES ($1, $2)
+			ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id`
+			var id int64
+			if err := tx.QueryRowContext(ctx, query, bookmark.UserID, *bookmark.Collection).Scan(&id); err != nil {
+				return err
+			}
+			collectionID = &id
+		}
+
+		query := `INSERT INTO bookmarks (user_id, post_id, collection_id) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, post_id) DO UPDATE SET collection_id = EXCLUDED.collection_id
+		RETURNING id, created_at`
+		return tx.QueryRowContext(ctx, query, bookmark.UserID, bookmark.PostID, collectionID).Scan(&bookmark.ID, &bookmark.CreatedAt)
+	})
+}
+
+func (s *PostgresBookmarkStore) Remove(ctx context.Context, userID, postID int64) error {
+	query := `DELETE FROM bookmarks WHERE user_id = $1 AND post_id = $2`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, query, userID, postID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresBookmarkStore) Exists(ctx context.Context, userID, postID int64) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM bookmarks WHERE user_id = $1 AND post_id = $2)`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	var exists bool
+	err := s.db.QueryRowContext(ctx, query, userID, postID).Scan(&exists)
+	return exists, err
+}
+
+// GetByUserID lists the user's bookmarks newest first, it returns the cursor of
+// the next page or an empty string on the last page.
+func (s *PostgresBookmarkStore) GetByUserID(ctx context.Context, userID int64, bq BookmarkQuery) ([]Bookmark, string, error) {
+	var after *Cursor
+	if bq.Cursor != "" {
+		c, err := DecodeCursor(bq.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &c
+	}
+
+	query := `
+	SELECT b.id, b.user_id, b.post_id, bc.name, b.created_at, p.title, p.tags, p.created_at, u.id, u.username
+	FROM bookmarks b
+	JOIN posts p ON p.id = b.post_id
+	JOIN users u ON u.id = p.user_id
+	LEFT JOIN bookmark_collections bc ON bc.id = b.collection_id
+	WHERE b.user_id = $1
+	    AND ($2 = '' OR bc.name = $2)
+	    AND ($3::timestamptz IS NULL OR (b.created_at, b.id) < ($3, $4))
+	ORDER BY b.created_at DESC, b.id DESC
+	LIMIT $5`
+
+	var afterTime *time.Time
+	var afterID int64
+	if after != nil {
+		afterTime, afterID = &after.CreatedAt, after.ID
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query, userID, bq.Collection, afterTime, afterID, bq.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	bookmarks := []Bookmark{}
+	for rows.Next() {
+		var b Bookmark
+		err := rows.Scan(&b.ID, &b.UserID, &b.PostID, &b.Collection, &b.CreatedAt, &b.Post.Title, pq.Array(&b.Post.Tags), &b.Post.CreatedAt, &b.Post.User.ID, &b.Post.User.Username)
+		if err != nil {
+			return nil, "", err
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(bookmarks) > bq.Limit {
+		bookmarks = bookmarks[:bq.Limit]
+		last := bookmarks[len(bookmarks)-1]
+		next = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return bookmarks, next, nil
+}
+
+func (s *PostgresBookmarkStore) GetCollections(ctx context.Context, userID int64) ([]BookmarkCollection, error) {
+	query := `
+	SELECT bc.id, bc.name, COUNT(b.id), bc.created_at
+	FROM bookmark_collections bc
+	LEFT JOIN bookmarks b ON b.collection_id = bc.id
+	WHERE bc.user_id = $1
+	GROUP BY bc.id
+	ORDER BY bc.name`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	collections := []BookmarkCollection{}
+	for rows.Next() {
+		var c BookmarkCollection
+		if err := rows.Scan(&c.ID, &c.Name, &c.Count, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	return collections, rows.Err()
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	BountyOpen    = "open"
+	BountyAwarded = "awarded"
+	BountyExpired = "expired"
+)
+
+type Bounty struct {
+	ID               int64     `json:"id"`
+	PostID           int64     `json:"post_id"`
+	UserID           int64     `json:"user_id"`
+	Amount           int       `json:"amount"`
+	Status           string    `json:"status"`
+	AwardedCommentID *int64    `json:"awarded_comment_id,omitempty"`
+	AwardedUserID    *int64    `json:"awarded_user_id,omitempty"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type PostgresBountyStore struct {
+	db *sql.DB
+}
+
+// Create opens the bounty and takes its amount from the sponsor's reputation.
+func (s *PostgresBountyStore) Create(ctx context.Context, bounty *Bounty) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var reputation int
+		query := `SELECT reputation FROM users WHERE id = $1 FOR UPDATE`
+		if err := tx.QueryRowContext(ctx, query, bounty.UserID).Scan(&reputation); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if reputation < bounty.Amount {
+			return ErrInsufficientReputation
+		}
+
+		query = `INSERT INTO bounties (post_id, user_id, amount, expires_at) VALUES ($1, $2, $3, $4) RETURNING id, status, created_at`
+		err := tx.QueryRowContext(ctx, query, bounty.PostID, bounty.UserID, bounty.Amount, bounty.ExpiresAt).Scan(&bounty.ID, &bounty.Status, &bounty.CreatedAt)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return ErrConflict
+			}
+			return err
+		}
+
+		e := ReputationEvent{
+			UserID:  bounty.UserID,
+			ActorID: bounty.UserID,
+			Type:    ReputationBountyOffered,
+			Points:  -bounty.Amount,
+			PostID:  &bounty.PostID,
+		}
+		return recordReputation(ctx, tx, e)
+	})
+}
+
+func (s *PostgresBountyStore) GetOpenByPostID(ctx context.Context, postID int64) (*Bounty, error) {
+	query := `SELECT id, post_id, user_id, amount, status, expires_at, created_at FROM bounties WHERE post_id = $1 AND status = 'open'`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	bounty := &Bounty{}
+	err := s.db.QueryRowContext(ctx, query, postID).Scan(&bounty.ID, &bounty.PostID, &bounty.UserID, &bounty.Amount, &bounty.Status, &bounty.ExpiresAt, &bounty.CreatedAt)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return bounty, nil
+}
+
+// ProcessExpired closes the open bounties past their expiry. Each bounty goes
+// to the accepted answer, or else to the highest voted top-level answer with a
+// positive score, as long as it was not written by the sponsor. Bounties with
+// no eligible answer expire without a refund. It returns the closed bounties.
+func (s *PostgresBountyStore) ProcessExpired(ctx context.Context) ([]Bounty, error) {
+	var closed []Bounty
+	for {
+		bounty, err := s.processNextExpired(ctx)
+		if err != nil {
+			return closed, err
+		}
+		if bounty == nil {
+			return closed, nil
+		}
+		closed = append(closed, *bounty)
+	}
+}
+
+func (s *PostgresBountyStore) processNextExpired(ctx context.Context) (*Bounty, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var bounty *Bounty
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		//skip locked rows so several instances can run the job at once
+		query := `
+		SELECT id, post_id, user_id, amount, expires_at, created_at
+		FROM bounties
+		WHERE status = 'open' AND expires_at <= NOW()
+		ORDER BY expires_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`
+		b := &Bounty{}
+		err := tx.QueryRowContext(ctx, query).Scan(&b.ID, &b.PostID, &b.UserID, &b.Amount, &b.ExpiresAt, &b.CreatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		query = `
+		SELECT c.id, c.user_id
+		FROM comments c
+		JOIN posts p ON p.id = c.post_id
+		WHERE c.post_id = $1 AND c.parent_id IS NULL AND c.deleted = false AND c.user_id <> $2
+		AND (c.id = p.accepted_comment_id OR c.score > 0)
+		ORDER BY (c.id = p.accepted_comment_id) DESC, c.score DESC, c.created_at
+		LIMIT 1`
+		var commentID, authorID int64
+		err = tx.QueryRowContext(ctx, query, b.PostID, b.UserID).Scan(&commentID, &authorID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			b.Status = BountyExpired
+		case err != nil:
+			return err
+		default:
+			b.Status = BountyAwarded
+			b.AwardedCommentID = &commentID
+			b.AwardedUserID = &authorID
+		}
+
+		query = `UPDATE bounties SET status = $1, awarded_comment_id = $2 WHERE id = $3`
+		if _, err := tx.ExecContext(ctx, query, b.Status, b.AwardedCommentID, b.ID); err != nil {
+			return err
+		}
+		bounty = b
+
+		if b.Status != BountyAwarded {
+			return nil
+		}
+		e := ReputationEvent{
+			UserID:    authorID,
+			ActorID:   b.UserID,
+			Type:      ReputationBountyAwarded,
+			Points:    b.Amount,
+			PostID:    &b.PostID,
+			CommentID: &commentID,
+		}
+		return recordReputation(ctx, tx, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bounty, nil
+}
@@ -10,20 +10,81 @@ import (
 func NewMockStore() Storage {
 	return Storage{
 		Users:          &MockUserStore{},
+		Trending:       &MockTrendingStore{},
+		Related:        &MockRelatedStore{},
+		Timelines:      &MockTimelineStore{},
 		RedisRateLimit: &MockRateLimitStore{},
 	}
 }
 
-type MockUserStore struct{}
+// MockUserStore returns an active user whatever the ID, with Role or the user
+// role when it is not set.
+type MockUserStore struct {
+	Role store.Role
+}
 
 func (m *MockUserStore) Get(ctx context.Context, userID int64) (*store.User, error) {
-	return &store.User{}, nil
+	role := m.Role
+	if role.Name == "" {
+		role = store.Role{ID: 1, Name: "user", Level: 1}
+	}
+	return &store.User{ID: userID, IsActive: true, Role: role}, nil
 }
 
 func (m *MockUserStore) Set(ctx context.Context, user *store.User) error {
 	return nil
 }
 
+func (m *MockUserStore) Delete(ctx context.Context, userID int64) error {
+	return nil
+}
+
+type MockTrendingStore struct{}
+
+func (m *MockTrendingStore) Get(ctx context.Context) ([]int64, bool, error) {
+	return nil, false, nil
+}
+
+func (m *MockTrendingStore) Set(ctx context.Context, scores []store.PostScore) error {
+	return nil
+}
+
+type MockRelatedStore struct{}
+
+func (m *MockRelatedStore) Get(ctx context.Context, postID int64) ([]store.RelatedPost, bool, error) {
+	return nil, false, nil
+}
+
+func (m *MockRelatedStore) Set(ctx context.Context, postID int64, related []store.RelatedPost) error {
+	return nil
+}
+
+func (m *MockRelatedStore) Delete(ctx context.Context, postIDs ...int64) error {
+	return nil
+}
+
+type MockTimelineStore struct{}
+
+func (m *MockTimelineStore) Push(ctx context.Context, userIDs []int64, postIDs ...int64) error {
+	return nil
+}
+
+func (m *MockTimelineStore) Remove(ctx context.Context, userIDs []int64, postIDs ...int64) error {
+	return nil
+}
+
+func (m *MockTimelineStore) Set(ctx context.Context, userID int64, postIDs []int64) error {
+	return nil
+}
+
+func (m *MockTimelineStore) Get(ctx context.Context, userID, beforeID int64, offset, count int) ([]int64, bool, bool, error) {
+	return nil, false, false, nil
+}
+
+func (m *MockTimelineStore) Delete(ctx context.Context, userID int64) error {
+	return nil
+}
+
 type MockRateLimitStore struct {
 	count int
 }
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+type RelatedRedisStorage struct {
+	rdb *redis.Client
+}
+
+// RelatedTimeExp bounds how long new posts take to show up as related to older
+// ones, edits invalidate the cached lists right away.
+var RelatedTimeExp time.Duration = time.Hour
+
+func relatedKey(postID int64) string {
+	return fmt.Sprintf("related-posts-%d", postID)
+}
+
+// relatedRefsKey holds the posts whose cached related list contains the post,
+// so they can be dropped when it changes.
+func relatedRefsKey(postID int64) string {
+	return fmt.Sprintf("related-refs-%d", postID)
+}
+
+// Get returns the posts related to the post, ok is false when they are not
+// cached.
+func (r *RelatedRedisStorage) Get(ctx context.Context, postID int64) ([]store.RelatedPost, bool, error) {
+	data, err := r.rdb.Get(ctx, relatedKey(postID)).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	var related []store.RelatedPost
+	if err := json.Unmarshal(data, &related); err != nil {
+		return nil, false, err
+	}
+	return related, true, nil
+}
+
+func (r *RelatedRedisStorage) Set(ctx context.Context, postID int64, related []store.RelatedPost) error {
+	data, err := json.Marshal(related)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetEx(ctx, relatedKey(postID), data, RelatedTimeExp)
+		for _, rp := range related {
+			pipe.SAdd(ctx, relatedRefsKey(rp.ID), postID)
+			pipe.Expire(ctx, relatedRefsKey(rp.ID), RelatedTimeExp)
+		}
+		return nil
+	})
+	return err
+}
+
+// Delete drops the related posts of the posts along with every cached list
+// that contains one of them.
+func (r *RelatedRedisStorage) Delete(ctx context.Context, postIDs ...int64) error {
+	var keys []string
+	for _, id := range postIDs {
+		refs, err := r.rdb.SMembers(ctx, relatedRefsKey(id)).Result()
+		if err != nil {
+			return err
+		}
+		keys = append(keys, relatedKey(id), relatedRefsKey(id))
+		for _, ref := range refs {
+			refID, err := strconv.ParseInt(ref, 10, 64)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, relatedKey(refID))
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.rdb.Del(ctx, keys...).Err()
+}
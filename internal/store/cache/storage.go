@@ -12,6 +12,23 @@ type Storage struct {
 	Users interface {
 		Get(context.Context, int64) (*store.User, error)
 		Set(context.Context, *store.User) error
+		Delete(context.Context, int64) error
+	}
+	Trending interface {
+		Get(context.Context) ([]int64, bool, error)
+		Set(context.Context, []store.PostScore) error
+	}
+	Related interface {
+		Get(ctx context.Context, postID int64) ([]store.RelatedPost, bool, error)
+		Set(ctx context.Context, postID int64, related []store.RelatedPost) error
+		Delete(ctx context.Context, postIDs ...int64) error
+	}
+	Timelines interface {
+		Push(ctx context.Context, userIDs []int64, postIDs ...int64) error
+		Remove(ctx context.Context, userIDs []int64, postIDs ...int64) error
+		Set(ctx context.Context, userID int64, postIDs []int64) error
+		Get(ctx context.Context, userID, beforeID int64, offset, count int) (postIDs []int64, complete, ok bool, err error)
+		Delete(ctx context.Context, userID int64) error
 	}
 	RedisRateLimit interface {
 		// GetCount(ctx context.Context, key string) (int, error)
@@ -23,6 +40,9 @@ type Storage struct {
 func NewRedisStorage(rdb *redis.Client) Storage {
 	return Storage{
 		Users:          &UserRedisStorage{rdb},
+		Trending:       &TrendingRedisStorage{rdb},
+		Related:        &RelatedRedisStorage{rdb},
+		Timelines:      &TimelineRedisStorage{rdb},
 		RedisRateLimit: &RateLimitRedisStore{rdb},
 	}
 }
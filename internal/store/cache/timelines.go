@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type TimelineRedisStorage struct {
+	rdb *redis.Client
+}
+
+var (
+	// TimelineSize is the number of posts kept per timeline, older pages are
+	// read from the database
+	TimelineSize = 800
+	// TimelineTimeExp drops the timelines of inactive users, they are rebuilt
+	// on their next read
+	TimelineTimeExp time.Duration = 7 * 24 * time.Hour
+)
+
+// timelineSentinel is a member kept in every timeline so that empty timelines
+// exist, it scores below every post.
+const timelineSentinel = "0"
+
+// timelinePushScript adds the posts in ARGV[2:] to the timelines in KEYS that
+// exist and trims them to ARGV[1] posts. Missing timelines are left alone, they
+// are built in full on their next read.
+var timelinePushScript = redis.NewScript(`
+for _, key in ipairs(KEYS) do
+	if redis.call('EXISTS', key) == 1 then
+		for i = 2, #ARGV do
+			redis.call('ZADD', key, ARGV[i], ARGV[i])
+		end
+		redis.call('ZREMRANGEBYRANK', key, 1, -(tonumber(ARGV[1]) + 1))
+	end
+end
+return 0
+`)
+
+func timelineKey(userID int64) string {
+	return fmt.Sprintf("timeline-%d", userID)
+}
+
+// Push adds the posts to the existing timelines of the users. Posts are scored
+// by their id, which follows their creation order.
+func (r *TimelineRedisStorage) Push(ctx context.Context, userIDs []int64, postIDs ...int64) error {
+	if len(userIDs) == 0 || len(postIDs) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(postIDs)+1)
+	args = append(args, TimelineSize)
+	for _, id := range postIDs {
+		args = append(args, id)
+	}
+
+	// the keys are sent in batches to bound the time the script blocks Redis
+	const batch = 500
+	for start := 0; start < len(userIDs); start += batch {
+		end := min(start+batch, len(userIDs))
+		keys := make([]string, 0, end-start)
+		for _, id := range userIDs[start:end] {
+			keys = append(keys, timelineKey(id))
+		}
+		if err := timelinePushScript.Run(ctx, r.rdb, keys, args...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove drops the posts from the timelines of the users.
+func (r *TimelineRedisStorage) Remove(ctx context.Context, userIDs []int64, postIDs ...int64) error {
+	if len(userIDs) == 0 || len(postIDs) == 0 {
+		return nil
+	}
+
+	members := make([]any, 0, len(postIDs))
+	for _, id := range postIDs {
+		members = append(members, id)
+	}
+	_, err := r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range userIDs {
+			pipe.ZRem(ctx, timelineKey(id), members...)
+		}
+		return nil
+	})
+	return err
+}
+
+// Set replaces the timeline of the user with the posts.
+func (r *TimelineRedisStorage) Set(ctx context.Context, userID int64, postIDs []int64) error {
+	members := make([]redis.Z, 0, len(postIDs)+1)
+	members = append(members, redis.Z{Score: 0, Member: timelineSentinel})
+	for _, id := range postIDs {
+		members = append(members, redis.Z{Score: float64(id), Member: id})
+	}
+
+	key := timelineKey(userID)
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.ZAdd(ctx, key, members...)
+		pipe.Expire(ctx, key, TimelineTimeExp)
+		return nil
+	})
+	return err
+}
+
+// Get returns up to count posts of the timeline of the user older than
+// beforeID, 0 for the newest, skipping offset posts. ok is false when the
+// timeline does not exist and complete is false when older posts may have been
+// trimmed from it.
+func (r *TimelineRedisStorage) Get(ctx context.Context, userID, beforeID int64, offset, count int) (postIDs []int64, complete, ok bool, err error) {
+	key := timelineKey(userID)
+	rangeBy := &redis.ZRangeBy{Min: "(0", Max: "+inf", Offset: int64(offset), Count: int64(count)}
+	if beforeID > 0 {
+		rangeBy.Max = "(" + strconv.FormatInt(beforeID, 10)
+	}
+
+	var members *redis.StringSliceCmd
+	var card *redis.IntCmd
+	_, err = r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		members = pipe.ZRevRangeByScore(ctx, key, rangeBy)
+		card = pipe.ZCard(ctx, key)
+		pipe.Expire(ctx, key, TimelineTimeExp)
+		return nil
+	})
+	if err != nil {
+		return nil, false, false, err
+	}
+	if card.Val() == 0 {
+		return nil, false, false, nil
+	}
+
+	postIDs = make([]int64, 0, len(members.Val()))
+	for _, m := range members.Val() {
+		id, err := strconv.ParseInt(m, 10, 64)
+		if err != nil {
+			return nil, false, false, err
+		}
+		postIDs = append(postIDs, id)
+	}
+	return postIDs, card.Val()-1 < int64(TimelineSize), true, nil
+}
+
+// Delete drops the timeline of the user so it is rebuilt on its next read.
+func (r *TimelineRedisStorage) Delete(ctx context.Context, userID int64) error {
+	return r.rdb.Del(ctx, timelineKey(userID)).Err()
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/theluminousartemis/inkspire/internal/store"
+)
+
+type TrendingRedisStorage struct {
+	rdb *redis.Client
+}
+
+// TrendingTimeExp drops the trending posts if the job stops refreshing them.
+var TrendingTimeExp time.Duration = time.Hour
+
+const (
+	trendingKey = "trending-posts"
+	// trendingComputedKey marks the trending posts as computed, it outlives an
+	// empty ranking for which redis keeps no sorted set.
+	trendingComputedKey = "trending-posts:computed"
+)
+
+// Get returns the ids of the trending posts, highest score first. ok is false
+// when the trending posts were not computed yet or expired.
+func (r *TrendingRedisStorage) Get(ctx context.Context) ([]int64, bool, error) {
+	var ranking *redis.StringSliceCmd
+	var computed *redis.IntCmd
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		ranking = pipe.ZRevRange(ctx, trendingKey, 0, -1)
+		computed = pipe.Exists(ctx, trendingComputedKey)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if computed.Val() == 0 {
+		return nil, false, nil
+	}
+
+	ids := make([]int64, 0, len(ranking.Val()))
+	for _, m := range ranking.Val() {
+		id, err := strconv.ParseInt(m, 10, 64)
+		if err != nil {
+			return nil, false, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, true, nil
+}
+
+// Set replaces the trending posts in a single transaction so readers never see
+// a partially written ranking. An empty ranking is cached as well.
+func (r *TrendingRedisStorage) Set(ctx context.Context, scores []store.PostScore) error {
+	members := make([]redis.Z, 0, len(scores))
+	for _, s := range scores {
+		members = append(members, redis.Z{Score: s.Score, Member: s.PostID})
+	}
+
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, trendingKey)
+		if len(members) > 0 {
+			pipe.ZAdd(ctx, trendingKey, members...)
+			pipe.Expire(ctx, trendingKey, TrendingTimeExp)
+		}
+		pipe.Set(ctx, trendingComputedKey, 1, TrendingTimeExp)
+		return nil
+	})
+	return err
+}
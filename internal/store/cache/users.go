@@ -46,3 +46,8 @@ func (r *UserRedisStorage) Set(ctx context.Context, user *store.User) error {
 	r.rdb.SetEx(ctx, cacheKey, json, UserTimeExp).Err()
 	return nil
 }
+
+func (r *UserRedisStorage) Delete(ctx context.Context, userID int64) error {
+	cacheKey := fmt.Sprintf("user-%v", userID)
+	return r.rdb.Del(ctx, cacheKey).Err()
+}
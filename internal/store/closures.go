@@ -0,0 +1,184 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	CloseReasonDuplicate    = "duplicate"
+	CloseReasonOffTopic     = "off_topic"
+	CloseReasonNeedsDetails = "needs_details"
+)
+
+var closeReasonMessages = map[string]string{
+	CloseReasonDuplicate:    "This question has been closed as a duplicate.",
+	CloseReasonOffTopic:     "This question has been closed as off-topic.",
+	CloseReasonNeedsDetails: "This question has been closed because it needs details or clarity.",
+}
+
+// duplicateOfMessage names the canonical question, the generic duplicate
+// message is kept when it was deleted and duplicate_of set to null.
+const duplicateOfMessage = "This question has been closed as a duplicate of post %d."
+
+// PostBanner describes why a post is closed, DuplicateURL links to the
+// canonical question when it was closed as a duplicate.
+type PostBanner struct {
+	Reason       string    `json:"reason"`
+	Message      string    `json:"message"`
+	DuplicateOf  *int64    `json:"duplicate_of,omitempty"`
+	DuplicateURL string    `json:"duplicate_url,omitempty"`
+	ClosedAt     time.Time `json:"closed_at"`
+}
+
+type PostClosure struct {
+	Reason      string
+	DuplicateOf *int64
+	ClosedBy    int64
+}
+
+type CloseVoteResult struct {
+	Votes  int  `json:"votes"`
+	Closed bool `json:"closed"`
+}
+
+// postClosure holds the nullable closure columns of a post while scanning.
+type postClosure struct {
+	reason      *string
+	duplicateOf *int64
+	closedAt    *time.Time
+}
+
+func (c postClosure) banner() *PostBanner {
+	if c.reason == nil {
+		return nil
+	}
+	banner := &PostBanner{
+		Reason:      *c.reason,
+		Message:     closeReasonMessages[*c.reason],
+		DuplicateOf: c.duplicateOf,
+	}
+	if c.closedAt != nil {
+		banner.ClosedAt = *c.closedAt
+	}
+	if *c.reason == CloseReasonDuplicate && c.duplicateOf != nil {
+		banner.Message = fmt.Sprintf(duplicateOfMessage, *c.duplicateOf)
+		banner.DuplicateURL = fmt.Sprintf("/v1/posts/%d", *c.duplicateOf)
+	}
+	return banner
+}
+
+func (s *PostgresPostStore) Close(ctx context.Context, postID int64, closure PostClosure) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	return closePost(ctx, s.db, postID, closure)
+}
+
+// Reopen clears the closure of the post and the close votes cast on it.
+func (s *PostgresPostStore) Reopen(ctx context.Context, postID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE posts SET closed_reason = NULL, duplicate_of = NULL, closed_by = NULL, closed_at = NULL
+		WHERE id = $1 AND closed_reason IS NOT NULL`
+		res, err := tx.ExecContext(ctx, query, postID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrConflict
+		}
+
+		query = `DELETE FROM post_close_votes WHERE post_id = $1`
+		_, err = tx.ExecContext(ctx, query, postID)
+		return err
+	})
+}
+
+// VoteToClose records the user's close vote and closes the post once it has
+// threshold votes, with the reason most voters picked.
+func (s *PostgresPostStore) VoteToClose(ctx context.Context, postID int64, closure PostClosure, threshold int) (*CloseVoteResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result := &CloseVoteResult{}
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var closed bool
+		query := `SELECT closed_reason IS NOT NULL FROM posts WHERE id = $1 FOR UPDATE`
+		if err := tx.QueryRowContext(ctx, query, postID).Scan(&closed); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if closed {
+			return ErrConflict
+		}
+
+		query = `INSERT INTO post_close_votes (post_id, user_id, reason, duplicate_of) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (post_id, user_id) DO UPDATE SET reason = EXCLUDED.reason, duplicate_of = EXCLUDED.duplicate_of`
+		if _, err := tx.ExecContext(ctx, query, postID, closure.ClosedBy, closure.Reason, closure.DuplicateOf); err != nil {
+			return err
+		}
+
+		query = `SELECT COUNT(*) FROM post_close_votes WHERE post_id = $1`
+		if err := tx.QueryRowContext(ctx, query, postID).Scan(&result.Votes); err != nil {
+			return err
+		}
+		if result.Votes < threshold {
+			return nil
+		}
+
+		var winner PostClosure
+		query = `
+		SELECT reason, duplicate_of
+		FROM post_close_votes
+		WHERE post_id = $1
+		GROUP BY reason, duplicate_of
+		ORDER BY COUNT(*) DESC, MIN(created_at)
+		LIMIT 1`
+		if err := tx.QueryRowContext(ctx, query, postID).Scan(&winner.Reason, &winner.DuplicateOf); err != nil {
+			return err
+		}
+		winner.ClosedBy = closure.ClosedBy
+
+		if err := closePost(ctx, tx, postID, winner); err != nil {
+			return err
+		}
+		result.Closed = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func closePost(ctx context.Context, db execer, postID int64, closure PostClosure) error {
+	query := `UPDATE posts SET closed_reason = $1, duplicate_of = $2, closed_by = $3, closed_at = NOW()
+	WHERE id = $4 AND closed_reason IS NULL`
+	res, err := db.ExecContext(ctx, query, closure.Reason, closure.DuplicateOf, closure.ClosedBy, postID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrConflict
+	}
+	return nil
+}
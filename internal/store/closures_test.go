@@ -0,0 +1,57 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPostClosureBanner(t *testing.T) {
+	duplicate, offTopic := CloseReasonDuplicate, CloseReasonOffTopic
+	target := int64(42)
+	closedAt := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		closure postClosure
+		want    *PostBanner
+	}{
+		{"open post", postClosure{}, nil},
+		{
+			name:    "off topic",
+			closure: postClosure{reason: &offTopic, closedAt: &closedAt},
+			want: &PostBanner{
+				Reason:   CloseReasonOffTopic,
+				Message:  "This question has been closed as off-topic.",
+				ClosedAt: closedAt,
+			},
+		},
+		{
+			name:    "duplicate",
+			closure: postClosure{reason: &duplicate, duplicateOf: &target, closedAt: &closedAt},
+			want: &PostBanner{
+				Reason:       CloseReasonDuplicate,
+				Message:      "This question has been closed as a duplicate of post 42.",
+				DuplicateOf:  &target,
+				DuplicateURL: "/v1/posts/42",
+				ClosedAt:     closedAt,
+			},
+		},
+		{
+			name:    "duplicate of a deleted post",
+			closure: postClosure{reason: &duplicate, closedAt: &closedAt},
+			want: &PostBanner{
+				Reason:   CloseReasonDuplicate,
+				Message:  "This question has been closed as a duplicate.",
+				ClosedAt: closedAt,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.closure.banner(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
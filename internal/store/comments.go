@@ -3,19 +3,30 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 )
 
 type Comment struct {
-	ID        int64       `json:"id"`
-	UserID    int64       `json:"user_id"`
-	PostID    int64       `json:"post_id"`
-	Content   string      `json:"content"`
-	CreatedAt time.Time   `json:"created_at"`
-	ParentID  *int64      `json:"parent_id,omitempty"`
-	Deleted   bool        `json:"-"`
-	User      CommentUser `json:"user"`
-	Replies   []*Comment  `json:"replies,omitempty"`
+	ID          int64          `json:"id"`
+	UserID      int64          `json:"user_id"`
+	PostID      int64          `json:"post_id"`
+	Content     string         `json:"content"`
+	ContentHTML string         `json:"content_html,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	EditedAt    *time.Time     `json:"edited_at,omitempty"`
+	ParentID    *int64         `json:"parent_id,omitempty"`
+	Score       int            `json:"score"`
+	Accepted    bool           `json:"accepted"`
+	Reactions   map[string]int `json:"reactions"`
+	Deleted     bool           `json:"-"`
+	User        CommentUser    `json:"user"`
+	Replies     []*Comment     `json:"replies,omitempty"`
+	// ReplyCount counts all the direct replies, some may not be listed in
+	// Replies
+	ReplyCount int `json:"reply_count"`
+	// MoreReplies is the cursor listing the replies left out of Replies
+	MoreReplies string `json:"more_replies,omitempty"`
 }
 
 type CommentUser struct {
@@ -23,15 +34,25 @@ type CommentUser struct {
 }
 
 type SwaggerCommentResponse struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
-	PostID    int64     `json:"post_id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	PostID      int64      `json:"post_id"`
+	Content     string     `json:"content"`
+	ContentHTML string     `json:"content_html,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
 	// Deleted   bool             `json:"deleted"`
-	ParentID *int64           `json:"parent_id,omitempty"`
-	User     CommentUser      `json:"user"`
-	Replies  []CommentShallow `json:"replies,omitempty"`
+	ParentID  *int64           `json:"parent_id,omitempty"`
+	Score     int              `json:"score"`
+	Accepted  bool             `json:"accepted"`
+	Reactions map[string]int   `json:"reactions"`
+	User      CommentUser      `json:"user"`
+	Replies   []CommentShallow `json:"replies,omitempty"`
+	// ReplyCount counts all the direct replies, some may not be listed in
+	// Replies
+	ReplyCount int `json:"reply_count"`
+	// MoreReplies is the cursor listing the replies left out of Replies
+	MoreReplies string `json:"more_replies,omitempty"`
 }
 
 type CommentShallow struct {
@@ -57,131 +78,366 @@ func (s *PostgresCommentStore) Create(ctx context.Context, comment *Comment) err
 	return nil
 }
 
-func (s *PostgresCommentStore) GetByPostID(ctx context.Context, postID int64) ([]*Comment, error) {
-	query :=
-		`
-	WITH RECURSIVE comment_tree AS (
-  SELECT
-    c.id,
-    c.user_id,
-    c.content,
-    c.created_at,
-    c.parent_id,
-    c.post_id,
-	c.deleted,
-    1 AS depth,
-    LPAD(c.id::text, 10, '0') AS path
-  FROM comments c
-  WHERE c.post_id = $1 AND c.parent_id IS NULL
-
-  UNION ALL
-
-  SELECT
-    child.id,
-    child.user_id,
-    child.content,
-    child.created_at,
-    child.parent_id,
-    child.post_id,
-	child.deleted,
-    ct.depth + 1,
-    ct.path || '.' || LPAD(child.id::text, 10, '0') AS path
-  FROM comments child
-  JOIN comment_tree ct ON ct.id = child.parent_id
-)
+// Delete soft deletes the comment. When it is the accepted answer of its post
+// the answer is unaccepted and the reputation its author earned is reversed,
+// the reversal events are returned.
+func (s *PostgresCommentStore) Delete(ctx context.Context, id int64) ([]ReputationEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
 
-SELECT
-  c.id, c.user_id, u.username, c.post_id,
-  c.content, c.created_at, c.parent_id, c.deleted
-FROM comment_tree c
-JOIN users u ON c.user_id = u.id
-ORDER BY path;
-	`
+	var events []ReputationEvent
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE comments SET content=$1, deleted = true WHERE id = $2`
+		res, err := tx.ExecContext(ctx, query, DeletedContent, id)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		var postID, authorID int64
+		query = `UPDATE posts SET accepted_comment_id = NULL WHERE accepted_comment_id = $1 RETURNING id, user_id`
+		if err := tx.QueryRowContext(ctx, query, id).Scan(&postID, &authorID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		e, err := acceptedAnswerEvent(ctx, tx, postID, id, authorID)
+		if err != nil {
+			return err
+		}
+		if e.UserID != authorID {
+			events = append(events, e.reversal())
+		}
+		return recordReputation(ctx, tx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (s *PostgresCommentStore) GetByID(ctx context.Context, id int64) (*Comment, error) {
+	query := `SELECT id,post_id, user_id, content, created_at, edited_at, parent_id, score, deleted FROM comments WHERE id = $1`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	row := s.db.QueryRowContext(ctx, query, id)
+	comment := &Comment{}
+	err := row.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.Content, &comment.CreatedAt, &comment.EditedAt, &comment.ParentID, &comment.Score, &comment.Deleted)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return comment, nil
+}
+
+// CommentEdit is a past version of a comment, PreviousContent is the content
+// the editor replaced at EditedAt.
+type CommentEdit struct {
+	ID              int64       `json:"id"`
+	CommentID       int64       `json:"comment_id"`
+	PreviousContent string      `json:"previous_content"`
+	EditorID        *int64      `json:"editor_id"`
+	Editor          CommentUser `json:"editor"`
+	EditedAt        time.Time   `json:"edited_at"`
+}
+
+// Update saves the new content of the live comment and records the content
+// it replaced in the edit history.
+func (s *PostgresCommentStore) Update(ctx context.Context, comment *Comment, editorID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var previous string
+		query := `SELECT content FROM comments WHERE id = $1 AND deleted IS NOT TRUE FOR UPDATE`
+		if err := tx.QueryRowContext(ctx, query, comment.ID).Scan(&previous); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		query = `INSERT INTO comment_edits (comment_id, editor_id, previous_content) VALUES ($1, $2, $3)`
+		if _, err := tx.ExecContext(ctx, query, comment.ID, editorID, previous); err != nil {
+			return err
+		}
+
+		query = `UPDATE comments SET content = $1, edited_at = NOW() WHERE id = $2 RETURNING edited_at`
+		return tx.QueryRowContext(ctx, query, comment.Content, comment.ID).Scan(&comment.EditedAt)
+	})
+}
 
+// GetEdits lists the edit history of the comment, newest first. The history
+// of deleted comments is not listed.
+func (s *PostgresCommentStore) GetEdits(ctx context.Context, commentID int64) ([]CommentEdit, error) {
+	query := `
+	SELECT e.id, e.comment_id, e.previous_content, e.editor_id, COALESCE(u.username, '[deleted]'), e.edited_at
+	FROM comment_edits e
+	JOIN comments c ON c.id = e.comment_id
+	LEFT JOIN users u ON u.id = e.editor_id
+	WHERE e.comment_id = $1 AND c.deleted IS NOT TRUE
+	ORDER BY e.id DESC
+	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
-	rows, err := s.db.QueryContext(ctx, query, postID)
+
+	rows, err := s.db.QueryContext(ctx, query, commentID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	comments := []*Comment{}
-	commentMap := make(map[int64]*Comment)
+
+	edits := []CommentEdit{}
 	for rows.Next() {
-		var comment Comment
-		comment.User = CommentUser{}
-		err := rows.Scan(
-			&comment.ID,
-			&comment.UserID,
-			&comment.User.Username,
-			&comment.PostID,
-			&comment.Content,
-			&comment.CreatedAt,
-			&comment.ParentID,
-			&comment.Deleted,
-		)
-		if err != nil {
+		var e CommentEdit
+		if err := rows.Scan(&e.ID, &e.CommentID, &e.PreviousContent, &e.EditorID, &e.Editor.Username, &e.EditedAt); err != nil {
 			return nil, err
 		}
-		c := &comment
-		commentMap[c.ID] = c
-		comments = append(comments, c)
-	}
-	for _, comment := range comments {
-		if comment.Deleted {
-			comment.Content = "[deleted]"
-			comment.User = CommentUser{
-				Username: "[deleted]",
-			}
-		}
+		edits = append(edits, e)
 	}
+	return edits, rows.Err()
+}
 
-	var roots []*Comment
-	for _, comment := range comments {
-		if comment.ParentID == nil {
-			roots = append(roots, comment)
-		} else if parent, ok := commentMap[*comment.ParentID]; ok {
-			parent.Replies = append(parent.Replies, comment)
+// commentOrders orders the comments sharing a parent for each sort of
+// CommentQuery, the accepted answer always comes first.
+var commentOrders = map[string]string{
+	"oldest": "c.created_at ASC, c.id ASC",
+	"newest": "c.created_at DESC, c.id DESC",
+	"top":    "c.score DESC, c.created_at ASC, c.id ASC",
+}
+
+// GetPage lists a page of the comment tree of the post: the top-level comments
+// or, when the query has a cursor, the replies to the comment of the cursor.
+// Every listed comment comes with up to cq.Replies of its replies, down to
+// cq.Depth levels, and a cursor to the replies left out. The returned cursor
+// points to the next page. A page resumes after the sibling of the cursor in
+// the current order, there is no page after a sibling that no longer exists.
+func (s *PostgresCommentStore) GetPage(ctx context.Context, postID int64, acceptedID *int64, cq CommentQuery) ([]*Comment, string, error) {
+	var at CommentCursor
+	if cq.Cursor != "" {
+		c, err := DecodeCommentCursor(cq.Cursor)
+		if err != nil {
+			return nil, "", err
 		}
+		at = c
 	}
-	return roots, nil
 
-	// return comments, nil
-}
+	query := `
+	WITH RECURSIVE ranked AS (
+	    SELECT c.id, c.user_id, c.post_id, c.content, c.created_at, c.edited_at, c.parent_id, c.deleted, c.score,
+	        ROW_NUMBER() OVER (PARTITION BY c.parent_id ORDER BY (c.id = $7::bigint) IS NOT TRUE, ` + commentOrders[cq.Sort] + `) AS rn,
+	        COUNT(*) OVER (PARTITION BY c.parent_id) AS siblings
+	    FROM comments c
+	    WHERE c.post_id = $1
+	),
+	anchor AS (
+	    SELECT a.rn FROM ranked a WHERE a.id = $3 AND COALESCE(a.parent_id, 0) = $2
+	    UNION ALL
+	    SELECT 0 WHERE $3::bigint = 0
+	),
+	tree AS (
+	    SELECT r.*, 1 AS depth, LPAD(r.rn::text, 10, '0') AS path
+	    FROM ranked r
+	    JOIN anchor ON r.rn > anchor.rn AND r.rn <= anchor.rn + $4
+	    WHERE COALESCE(r.parent_id, 0) = $2
+
+	    UNION ALL
 
-func (s *PostgresCommentStore) Delete(ctx context.Context, id int64) error {
-	query := `UPDATE comments SET content=$1, deleted = true WHERE id = $2`
+	    SELECT child.*, t.depth + 1, t.path || '.' || LPAD(child.rn::text, 10, '0')
+	    FROM ranked child
+	    JOIN tree t ON child.parent_id = t.id
+	    WHERE t.depth < $5 AND child.rn <= $6
+	)
+	SELECT
+	    c.id, c.user_id, u.username, c.post_id,
+	    c.content, c.created_at, c.edited_at, c.parent_id, c.deleted, c.score,
+	    c.rn, c.siblings,
+	    (SELECT COUNT(*) FROM comments ch WHERE ch.parent_id = c.id),
+	    ` + reactionCountsQuery(commentReactionTarget, "c") + `
+	FROM tree c
+	JOIN users u ON c.user_id = u.id
+	ORDER BY c.path
+	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
-	res, err := s.db.ExecContext(ctx, query, DeletedContent, id)
+
+	rows, err := s.db.QueryContext(ctx, query, postID, at.ParentID, at.AfterID, cq.Limit, cq.Depth, cq.Replies, acceptedID)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return err
+	defer rows.Close()
+
+	roots := []*Comment{}
+	var lastRank, siblings int
+	comments := make(map[int64]*Comment)
+	for rows.Next() {
+		c := &Comment{}
+		var rank, rowSiblings int
+		err := rows.Scan(
+			&c.ID,
+			&c.UserID,
+			&c.User.Username,
+			&c.PostID,
+			&c.Content,
+			&c.CreatedAt,
+			&c.EditedAt,
+			&c.ParentID,
+			&c.Deleted,
+			&c.Score,
+			&rank,
+			&rowSiblings,
+			&c.ReplyCount,
+			(*reactionCounts)(&c.Reactions),
+		)
+		if err != nil {
+			return nil, "", err
+		}
+		maskDeleted(c)
+		c.Accepted = acceptedID != nil && c.ID == *acceptedID
+
+		comments[c.ID] = c
+		if parent, ok := comments[parentOf(c)]; ok {
+			parent.Replies = append(parent.Replies, c)
+		} else {
+			roots = append(roots, c)
+			lastRank, siblings = rank, rowSiblings
+		}
 	}
-	if rows == 0 {
-		return ErrNotFound
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	for _, c := range comments {
+		if len(c.Replies) < c.ReplyCount {
+			more := CommentCursor{ParentID: c.ID}
+			if len(c.Replies) > 0 {
+				more.AfterID = c.Replies[len(c.Replies)-1].ID
+			}
+			c.MoreReplies = more.Encode()
+		}
 	}
-	return nil
 
+	var next string
+	if lastRank < siblings {
+		next = CommentCursor{ParentID: at.ParentID, AfterID: roots[len(roots)-1].ID}.Encode()
+	}
+	return roots, next, nil
 }
 
-func (s *PostgresCommentStore) GetByID(ctx context.Context, id int64) (*Comment, error) {
-	query := `SELECT id,post_id, user_id, content, created_at, parent_id FROM comments WHERE id = $1`
+// GetPath lists the comment preceded by its ancestors, starting with the
+// top-level comment of the thread. Replies are not listed.
+func (s *PostgresCommentStore) GetPath(ctx context.Context, commentID int64) ([]*Comment, error) {
+	query := `
+	WITH RECURSIVE path AS (
+	    SELECT c.id, c.user_id, c.post_id, c.content, c.created_at, c.edited_at, c.parent_id, c.deleted, c.score, 0 AS height
+	    FROM comments c
+	    WHERE c.id = $1
+
+	    UNION ALL
+
+	    SELECT parent.id, parent.user_id, parent.post_id, parent.content, parent.created_at, parent.edited_at,
+	        parent.parent_id, parent.deleted, parent.score, path.height + 1
+	    FROM comments parent
+	    JOIN path ON parent.id = path.parent_id
+	)
+	SELECT
+	    c.id, c.user_id, u.username, c.post_id,
+	    c.content, c.created_at, c.edited_at, c.parent_id, c.deleted, c.score,
+	    (SELECT COUNT(*) FROM comments ch WHERE ch.parent_id = c.id),
+	    ` + reactionCountsQuery(commentReactionTarget, "c") + `
+	FROM path c
+	JOIN users u ON c.user_id = u.id
+	ORDER BY c.height DESC
+	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
-	row := s.db.QueryRowContext(ctx, query, id)
-	comment := &Comment{}
-	err := row.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.Content, &comment.CreatedAt, &comment.ParentID)
+
+	rows, err := s.db.QueryContext(ctx, query, commentID)
 	if err != nil {
-		switch err {
-		case sql.ErrNoRows:
-			return nil, ErrNotFound
-		default:
+		return nil, err
+	}
+	defer rows.Close()
+
+	var path []*Comment
+	for rows.Next() {
+		c := &Comment{}
+		err := rows.Scan(
+			&c.ID,
+			&c.UserID,
+			&c.User.Username,
+			&c.PostID,
+			&c.Content,
+			&c.CreatedAt,
+			&c.EditedAt,
+			&c.ParentID,
+			&c.Deleted,
+			&c.Score,
+			&c.ReplyCount,
+			(*reactionCounts)(&c.Reactions),
+		)
+		if err != nil {
 			return nil, err
 		}
+		maskDeleted(c)
+		path = append(path, c)
 	}
-	return comment, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return nil, ErrNotFound
+	}
+	return path, nil
+}
+
+// GetDepth returns the nesting depth of the comment, 1 for a top-level
+// comment.
+func (s *PostgresCommentStore) GetDepth(ctx context.Context, commentID int64) (int, error) {
+	query := `
+	WITH RECURSIVE path AS (
+	    SELECT id, parent_id FROM comments WHERE id = $1
+	    UNION ALL
+	    SELECT c.id, c.parent_id FROM comments c JOIN path ON c.id = path.parent_id
+	)
+	SELECT COUNT(*) FROM path
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var depth int
+	if err := s.db.QueryRowContext(ctx, query, commentID).Scan(&depth); err != nil {
+		return 0, err
+	}
+	if depth == 0 {
+		return 0, ErrNotFound
+	}
+	return depth, nil
+}
+
+// maskDeleted hides the author of a deleted comment, it is kept in listings so
+// its replies keep their place.
+func maskDeleted(c *Comment) {
+	if c.Deleted {
+		c.Content = "[deleted]"
+		c.User = CommentUser{Username: "[deleted]"}
+	}
+}
+
+func parentOf(c *Comment) int64 {
+	if c.ParentID == nil {
+		return 0
+	}
+	return *c.ParentID
 }
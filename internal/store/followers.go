@@ -22,7 +22,7 @@ func (s *PostgresFollowerStore) Follow(ctx context.Context, followerID, userID i
 	query := `INSERT INTO followers (user_id, follower_id) VALUES ($1, $2)`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
-	_, err := s.db.ExecContext(ctx, query, followerID, userID)
+	_, err := s.db.ExecContext(ctx, query, userID, followerID)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
 			return ErrFollowConflict
@@ -44,3 +44,14 @@ func (s *PostgresFollowerStore) Unfollow(ctx context.Context, followerID, userID
 	_, err := s.db.ExecContext(ctx, query, userID, followerID)
 	return err
 }
+
+// IsFollowing reports whether followerID follows userID.
+func (s *PostgresFollowerStore) IsFollowing(ctx context.Context, followerID, userID int64) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM followers WHERE user_id = $1 AND follower_id = $2)`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var following bool
+	err := s.db.QueryRowContext(ctx, query, userID, followerID).Scan(&following)
+	return following, err
+}
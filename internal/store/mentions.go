@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// MaxMentions bounds the number of users a post or a comment can mention.
+const MaxMentions = 10
+
+// mentionPattern matches @username not preceded by a word character, so
+// e-mail addresses are not taken for mentions. Usernames may hold dots and
+// dashes but not end with one.
+var mentionPattern = regexp.MustCompile(`(^|[^A-Za-z0-9_@])@([A-Za-z0-9_](?:[A-Za-z0-9_.-]*[A-Za-z0-9_])?)`)
+
+// Mention is a user mentioned in a post, or in one of its comments when
+// CommentID is set.
+type Mention struct {
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"-"`
+	PostID    int64  `json:"post_id"`
+	CommentID *int64 `json:"comment_id,omitempty"`
+}
+
+// MentionSource is the post or comment holding mentions, AuthorID wrote it.
+type MentionSource struct {
+	AuthorID  int64
+	PostID    int64
+	CommentID *int64
+}
+
+// ParseMentions lists the distinct usernames mentioned in the content in order
+// of appearance, up to MaxMentions.
+func ParseMentions(content string) []string {
+	var usernames []string
+	seen := map[string]bool{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		key := strings.ToLower(m[2])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		usernames = append(usernames, m[2])
+		if len(usernames) == MaxMentions {
+			break
+		}
+	}
+	return usernames
+}
+
+// ReplaceMentions calls fn with every mention of the content and its
+// username, replacing the mention with the result. The character before the
+// @ is kept.
+func ReplaceMentions(content string, fn func(mention, username string) string) string {
+	return mentionPattern.ReplaceAllStringFunc(content, func(s string) string {
+		m := mentionPattern.FindStringSubmatch(s)
+		return m[1] + fn(s[len(m[1]):], m[2])
+	})
+}
+
+type PostgresMentionStore struct {
+	db *sql.DB
+}
+
+// Set replaces the mentions of the source with the active users among the
+// usernames, matched regardless of case. It returns all the mentions of the
+// source and the ones whose user was never notified of a mention by the
+// source, those are recorded as notified.
+func (s *PostgresMentionStore) Set(ctx context.Context, src MentionSource, usernames []string) ([]Mention, []Mention, error) {
+	lowered := make([]string, len(usernames))
+	for i, u := range usernames {
+		lowered[i] = strings.ToLower(u)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	mentions := []Mention{}
+	var added []Mention
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `SELECT id, username, email FROM users WHERE lower(username) = ANY($1) AND is_active = true`, pq.Array(lowered))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		ids := []int64{}
+		for rows.Next() {
+			m := Mention{PostID: src.PostID, CommentID: src.CommentID}
+			if err := rows.Scan(&m.UserID, &m.Username, &m.Email); err != nil {
+				return err
+			}
+			mentions = append(mentions, m)
+			ids = append(ids, m.UserID)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+		DELETE FROM mentions
+		WHERE post_id = $1 AND comment_id IS NOT DISTINCT FROM $2 AND NOT (user_id = ANY($3))`,
+			src.PostID, src.CommentID, pq.Array(ids))
+		if err != nil {
+			return err
+		}
+
+		for _, m := range mentions {
+			_, err := tx.ExecContext(ctx, `
+			INSERT INTO mentions (user_id, author_id, post_id, comment_id)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING`,
+				m.UserID, src.AuthorID, src.PostID, src.CommentID)
+			if err != nil {
+				return err
+			}
+
+			//removing a mention and restoring it does not notify the user again
+			res, err := tx.ExecContext(ctx, `
+			INSERT INTO mention_notifications (user_id, post_id, comment_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`,
+				m.UserID, src.PostID, src.CommentID)
+			if err != nil {
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				added = append(added, m)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return mentions, added, nil
+}
+
+// GetByPostID lists the mentions of the post and of its comments.
+func (s *PostgresMentionStore) GetByPostID(ctx context.Context, postID int64) ([]Mention, error) {
+	query := `
+	SELECT m.user_id, u.username, m.post_id, m.comment_id
+	FROM mentions m
+	JOIN users u ON u.id = m.user_id
+	WHERE m.post_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mentions []Mention
+	for rows.Next() {
+		var m Mention
+		if err := rows.Scan(&m.UserID, &m.Username, &m.PostID, &m.CommentID); err != nil {
+			return nil, err
+		}
+		mentions = append(mentions, m)
+	}
+	return mentions, rows.Err()
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"none", "no mentions here", nil},
+		{"start and middle", "@alice thanks, cc @bob.", []string{"alice", "bob"}},
+		{"dots and dashes", "ask @jane.doe-2 or @x_y", []string{"jane.doe-2", "x_y"}},
+		{"trailing dot", "thanks @alice.", []string{"alice"}},
+		{"duplicates regardless of case", "@Alice and @alice", []string{"Alice"}},
+		{"e-mail address", "write to bob@example.com", nil},
+		{"double at", "@@alice", nil},
+		{"after punctuation", "(@alice) @bob,", []string{"alice", "bob"}},
+		{"lone at", "meet @ noon", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMentions(tt.content); !slices.Equal(got, tt.want) {
+				t.Errorf("ParseMentions(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMentionsLimit(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < MaxMentions+5; i++ {
+		b.WriteString(" @user" + strings.Repeat("x", i))
+	}
+	if got := ParseMentions(b.String()); len(got) != MaxMentions {
+		t.Errorf("got %d mentions, want %d", len(got), MaxMentions)
+	}
+}
+
+func TestReplaceMentions(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"@alice", "[alice]"},
+		{"hi @alice and @bob!", "hi [alice] and [bob]!"},
+		{"(@alice)", "([alice])"},
+		{"bob@example.com", "bob@example.com"},
+		{"thanks @alice.", "thanks [alice]."},
+	}
+	for _, tt := range tests {
+		got := ReplaceMentions(tt.content, func(mention, username string) string {
+			if mention != "@"+username {
+				t.Errorf("mention %q does not match username %q", mention, username)
+			}
+			return "[" + username + "]"
+		})
+		if got != tt.want {
+			t.Errorf("ReplaceMentions(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -1,17 +1,288 @@
 package store
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/theluminousartemis/inkspire/internal/ranking"
 )
 
 func NewMockStore() Storage {
 	return Storage{
-		Users: &MockUserStore{},
+		Posts:         &MockPostStore{},
+		Users:         &MockUserStore{},
+		Comments:      &MockCommentStore{},
+		Roles:         &MockRoleStore{},
+		Votes:         &MockVoteStore{},
+		Badges:        &MockBadgeStore{},
+		Bookmarks:     &MockBookmarkStore{},
+		Tags:          NewMockTagStore(),
+		Reactions:     &MockReactionStore{},
+		Mentions:      &MockMentionStore{},
+		Search:        &MockSearchStore{},
+		Notifications: &MockNotificationStore{},
+		Followers:     &MockFollowerStore{},
 	}
 }
 
+// mockEpoch dates the records created by the mock stores.
+var mockEpoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// mockPostAuthors are the posts known to the mock stores and their authors.
+var mockPostAuthors = map[int64]int64{1: 1, 2: 2}
+
+// mockComments are the comments known to the mock stores. Comment 1 is a
+// top-level comment of user 2 on post 1 and comment 2 their reply to it,
+// comment 3 is a top-level comment of user 1 on post 2 and comment 4 a second
+// top-level comment of user 1 on post 1.
+var mockComments = map[int64]Comment{
+	1: {ID: 1, PostID: 1, UserID: 2, Content: "Answer"},
+	2: {ID: 2, PostID: 1, UserID: 2, ParentID: int64Ptr(1), Content: "Reply"},
+	3: {ID: 3, PostID: 2, UserID: 1, Content: "Other answer"},
+	4: {ID: 4, PostID: 1, UserID: 1, Content: "Second answer"},
+}
+
+// mockFeed is the user feed of the mock post store, posts 2 and 3 share their
+// creation time so the keyset pages have to break the tie on the id.
+var mockFeed = []PostWithMetadata{
+	{Post: Post{ID: 1, UserID: 1, Title: "First", CreatedAt: mockEpoch}},
+	{Post: Post{ID: 2, UserID: 2, Title: "Second", CreatedAt: mockEpoch.Add(time.Hour)}},
+	{Post: Post{ID: 3, UserID: 2, Title: "Third", CreatedAt: mockEpoch.Add(time.Hour)}},
+	{Post: Post{ID: 4, UserID: 1, Title: "Fourth", CreatedAt: mockEpoch.Add(2 * time.Hour)}},
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+// MockPostStore knows post 1, written by user 1, and post 2, written by user
+// 2. Other posts are not found. Accepted holds the accepted answer by post,
+// Closures the closure of closed posts and CloseVotes the close votes by post
+// then voter. FeedQuery is the last query of the user feed.
+type MockPostStore struct {
+	mu         sync.Mutex
+	Accepted   map[int64]int64
+	Closures   map[int64]PostClosure
+	CloseVotes map[int64]map[int64]PostClosure
+	FeedQuery  PagintatedFeedQuery
+}
+
+func (m *MockPostStore) Create(ctx context.Context, post *Post) error {
+	return nil
+}
+
+func (m *MockPostStore) GetByID(ctx context.Context, postID int64) (*Post, error) {
+	authorID, ok := mockPostAuthors[postID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	post := &Post{ID: postID, Title: "Post", UserID: authorID, User: PostUser{ID: authorID}}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if accepted, ok := m.Accepted[postID]; ok {
+		post.AcceptedCommentID = &accepted
+	}
+	if closure, ok := m.Closures[postID]; ok {
+		closedAt := time.Now()
+		post.Banner = postClosure{reason: &closure.Reason, duplicateOf: closure.DuplicateOf, closedAt: &closedAt}.banner()
+	}
+	return post, nil
+}
+
+func (m *MockPostStore) Delete(ctx context.Context, postID int64) error {
+	return nil
+}
+
+func (m *MockPostStore) Update(ctx context.Context, post *Post) error {
+	return nil
+}
+
+func (m *MockPostStore) UpdateCommentSettings(ctx context.Context, postID int64, settings CommentSettings) error {
+	return nil
+}
+
+func (m *MockPostStore) SetAcceptedAnswer(ctx context.Context, postID int64, commentID *int64) ([]ReputationEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Accepted == nil {
+		m.Accepted = make(map[int64]int64)
+	}
+	if commentID == nil {
+		delete(m.Accepted, postID)
+	} else {
+		m.Accepted[postID] = *commentID
+	}
+	return nil, nil
+}
+
+func (m *MockPostStore) Close(ctx context.Context, postID int64, closure PostClosure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.close(postID, closure)
+}
+
+func (m *MockPostStore) close(postID int64, closure PostClosure) error {
+	if _, ok := m.Closures[postID]; ok {
+		return ErrConflict
+	}
+	if m.Closures == nil {
+		m.Closures = make(map[int64]PostClosure)
+	}
+	m.Closures[postID] = closure
+	return nil
+}
+
+func (m *MockPostStore) Reopen(ctx context.Context, postID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.Closures[postID]; !ok {
+		return ErrConflict
+	}
+	delete(m.Closures, postID)
+	delete(m.CloseVotes, postID)
+	return nil
+}
+
+// VoteToClose closes the post with the closure most voters picked, ties going
+// to the voter with the lowest ID.
+func (m *MockPostStore) VoteToClose(ctx context.Context, postID int64, closure PostClosure, threshold int) (*CloseVoteResult, error) {
+	if _, ok := mockPostAuthors[postID]; !ok {
+		return nil, ErrNotFound
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.Closures[postID]; ok {
+		return nil, ErrConflict
+	}
+	if m.CloseVotes == nil {
+		m.CloseVotes = make(map[int64]map[int64]PostClosure)
+	}
+	if m.CloseVotes[postID] == nil {
+		m.CloseVotes[postID] = make(map[int64]PostClosure)
+	}
+	votes := m.CloseVotes[postID]
+	votes[closure.ClosedBy] = closure
+
+	result := &CloseVoteResult{Votes: len(votes)}
+	if result.Votes < threshold {
+		return result, nil
+	}
+
+	voters := make([]int64, 0, len(votes))
+	for voter := range votes {
+		voters = append(voters, voter)
+	}
+	slices.Sort(voters)
+	var winner PostClosure
+	best := 0
+	for _, voter := range voters {
+		count := 0
+		for _, v := range votes {
+			if sameClosure(v, votes[voter]) {
+				count++
+			}
+		}
+		if count > best {
+			winner, best = votes[voter], count
+		}
+	}
+	winner.ClosedBy = closure.ClosedBy
+	result.Closed = true
+	return result, m.close(postID, winner)
+}
+
+func sameClosure(a, b PostClosure) bool {
+	if a.Reason != b.Reason || (a.DuplicateOf == nil) != (b.DuplicateOf == nil) {
+		return false
+	}
+	return a.DuplicateOf == nil || *a.DuplicateOf == *b.DuplicateOf
+}
+
+// GetUserFeed pages mockFeed by creation time like the real store, the other
+// sorts list the posts newest first.
+func (m *MockPostStore) GetUserFeed(ctx context.Context, userID int64, fq PagintatedFeedQuery) ([]PostWithMetadata, string, error) {
+	m.mu.Lock()
+	m.FeedQuery = fq
+	m.mu.Unlock()
+
+	var after *Cursor
+	if fq.Cursor != "" {
+		c, err := DecodeCursor(fq.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &c
+	}
+
+	posts := slices.Clone(mockFeed)
+	if fq.Sort != "asc" {
+		slices.Reverse(posts)
+	}
+	feed := []PostWithMetadata{}
+	for _, p := range posts {
+		if fq.Since != nil && p.CreatedAt.Before(*fq.Since) || fq.Until != nil && !p.CreatedAt.Before(*fq.Until) {
+			continue
+		}
+		if after != nil {
+			order := p.CreatedAt.Compare(after.CreatedAt)
+			if order == 0 {
+				order = cmp.Compare(p.ID, after.ID)
+			}
+			if fq.Sort == "asc" && order <= 0 || fq.Sort != "asc" && order >= 0 {
+				continue
+			}
+		}
+		feed = append(feed, p)
+	}
+
+	feed = feed[min(fq.Offset, len(feed)):]
+	if !fq.Keyset() {
+		return feed[:min(fq.Limit, len(feed))], "", nil
+	}
+	var next string
+	if len(feed) > fq.Limit {
+		feed = feed[:fq.Limit]
+		last := feed[len(feed)-1]
+		next = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return feed, next, nil
+}
+
+func (m *MockPostStore) GetUserFeedPosts(ctx context.Context, userID int64, postIDs []int64) ([]PostWithMetadata, error) {
+	return []PostWithMetadata{}, nil
+}
+
+func (m *MockPostStore) GetRankedUserFeed(ctx context.Context, userID int64, fq PagintatedFeedQuery, weights ranking.Weights, candidates int) ([]PostWithMetadata, error) {
+	return []PostWithMetadata{}, nil
+}
+
+func (m *MockPostStore) GetTagFeed(ctx context.Context, userID int64, slug string, fq PagintatedFeedQuery) ([]PostWithMetadata, string, error) {
+	return []PostWithMetadata{}, "", nil
+}
+
+func (m *MockPostStore) GetExploreFeed(ctx context.Context, userID int64, eq ExploreQuery, trending []int64) ([]PostWithMetadata, error) {
+	return []PostWithMetadata{}, nil
+}
+
+func (m *MockPostStore) GetPublished(ctx context.Context, authorID int64, tag string, limit int) ([]PostWithMetadata, error) {
+	return []PostWithMetadata{}, nil
+}
+
+func (m *MockPostStore) GetTrendingScores(ctx context.Context, since time.Time, gravity float64, limit int) ([]PostScore, error) {
+	return []PostScore{}, nil
+}
+
+func (m *MockPostStore) GetRelated(ctx context.Context, postID int64, limit int) ([]RelatedPost, error) {
+	return []RelatedPost{}, nil
+}
+
 type MockUserStore struct{}
 
 func (m *MockUserStore) Create(ctx context.Context, tx *sql.Tx, user *User) error {
@@ -37,3 +308,875 @@ func (m *MockUserStore) Delete(ctx context.Context, userID int64) error {
 func (m *MockUserStore) GetUserByEmail(ctx context.Context, email string) (*User, error) {
 	return &User{}, nil
 }
+
+type MockBadgeStore struct{}
+
+func (m *MockBadgeStore) Create(ctx context.Context, badge *Badge) error {
+	return nil
+}
+
+func (m *MockBadgeStore) GetAll(ctx context.Context) ([]Badge, error) {
+	return []Badge{}, nil
+}
+
+func (m *MockBadgeStore) Delete(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (m *MockBadgeStore) GetUnawarded(ctx context.Context, userID int64, metrics []string) ([]Badge, error) {
+	return nil, nil
+}
+
+func (m *MockBadgeStore) GetByUserID(ctx context.Context, userID int64) ([]UserBadge, error) {
+	return []UserBadge{}, nil
+}
+
+func (m *MockBadgeStore) Award(ctx context.Context, userID, badgeID int64) (bool, error) {
+	return true, nil
+}
+
+func (m *MockBadgeStore) Metric(ctx context.Context, userID int64, metric string) (int, error) {
+	return 0, nil
+}
+
+func (m *MockBadgeStore) RecordActivity(ctx context.Context, userID int64) error {
+	return nil
+}
+
+// MockCommentStore knows the mock comments, other comments are not found.
+// Edits holds the edits in the order they were made and Query the last query
+// of a page of comments. Created comments get the ids following the mock
+// comments but are not kept.
+type MockCommentStore struct {
+	mu      sync.Mutex
+	Edits   []CommentEdit
+	Query   CommentQuery
+	content map[int64]string
+	lastID  int64
+}
+
+func (m *MockCommentStore) Create(ctx context.Context, comment *Comment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID = max(m.lastID, int64(len(mockComments))) + 1
+	comment.ID = m.lastID
+	comment.CreatedAt = mockEpoch
+	return nil
+}
+
+func (m *MockCommentStore) Delete(ctx context.Context, id int64) ([]ReputationEvent, error) {
+	return nil, nil
+}
+
+func (m *MockCommentStore) GetByID(ctx context.Context, id int64) (*Comment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	comment, ok := m.comment(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return comment, nil
+}
+
+// comment copies the mock comment with its edited content.
+func (m *MockCommentStore) comment(id int64) (*Comment, bool) {
+	comment, ok := mockComments[id]
+	if !ok {
+		return nil, false
+	}
+	if content, ok := m.content[id]; ok {
+		comment.Content = content
+	}
+	comment.CreatedAt = time.Now()
+	comment.User = CommentUser{Username: fmt.Sprintf("user%d", comment.UserID)}
+	return &comment, true
+}
+
+func (m *MockCommentStore) Update(ctx context.Context, comment *Comment, editorID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous, ok := m.content[comment.ID]
+	if !ok {
+		previous = mockComments[comment.ID].Content
+	}
+	if m.content == nil {
+		m.content = map[int64]string{}
+	}
+	m.content[comment.ID] = comment.Content
+
+	editedAt := mockEpoch
+	comment.EditedAt = &editedAt
+	m.Edits = append(m.Edits, CommentEdit{
+		ID:              int64(len(m.Edits) + 1),
+		CommentID:       comment.ID,
+		PreviousContent: previous,
+		EditorID:        &editorID,
+		Editor:          CommentUser{Username: fmt.Sprintf("user%d", editorID)},
+		EditedAt:        mockEpoch,
+	})
+	return nil
+}
+
+func (m *MockCommentStore) GetEdits(ctx context.Context, commentID int64) ([]CommentEdit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	edits := []CommentEdit{}
+	for _, edit := range slices.Backward(m.Edits) {
+		if edit.CommentID == commentID {
+			edits = append(edits, edit)
+		}
+	}
+	return edits, nil
+}
+
+// GetPage pages the mock comments of the post like the real store. All the
+// comments share their score, so the top sort lists them oldest first.
+func (m *MockCommentStore) GetPage(ctx context.Context, postID int64, acceptedID *int64, cq CommentQuery) ([]*Comment, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Query = cq
+
+	var at CommentCursor
+	if cq.Cursor != "" {
+		c, err := DecodeCommentCursor(cq.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		at = c
+	}
+
+	siblings := m.replies(postID, at.ParentID, acceptedID, cq.Sort)
+	start := 0
+	if at.AfterID != 0 {
+		i := slices.IndexFunc(siblings, func(c *Comment) bool { return c.ID == at.AfterID })
+		if i < 0 {
+			return []*Comment{}, "", nil
+		}
+		start = i + 1
+	}
+	roots := siblings[start:min(start+cq.Limit, len(siblings))]
+	for _, c := range roots {
+		m.tree(c, acceptedID, cq, 1)
+	}
+
+	var next string
+	if start+len(roots) < len(siblings) {
+		next = CommentCursor{ParentID: at.ParentID, AfterID: roots[len(roots)-1].ID}.Encode()
+	}
+	return roots, next, nil
+}
+
+// replies lists the replies to the comment of the post, or its top-level
+// comments when parentID is 0, the accepted answer first.
+func (m *MockCommentStore) replies(postID, parentID int64, acceptedID *int64, sort string) []*Comment {
+	replies := []*Comment{}
+	for id, c := range mockComments {
+		if c.PostID == postID && parentOf(&c) == parentID {
+			comment, _ := m.comment(id)
+			comment.Accepted = acceptedID != nil && id == *acceptedID
+			replies = append(replies, comment)
+		}
+	}
+	slices.SortFunc(replies, func(a, b *Comment) int {
+		if a.Accepted != b.Accepted {
+			if a.Accepted {
+				return -1
+			}
+			return 1
+		}
+		if sort == "newest" {
+			return cmp.Compare(b.ID, a.ID)
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return replies
+}
+
+// tree attaches up to cq.Replies replies to the comment at the depth, down to
+// cq.Depth levels, with a cursor to the replies left out.
+func (m *MockCommentStore) tree(c *Comment, acceptedID *int64, cq CommentQuery, depth int) {
+	replies := m.replies(c.PostID, c.ID, acceptedID, cq.Sort)
+	c.ReplyCount = len(replies)
+	if depth < cq.Depth {
+		c.Replies = replies[:min(cq.Replies, len(replies))]
+		for _, reply := range c.Replies {
+			m.tree(reply, acceptedID, cq, depth+1)
+		}
+	}
+	if len(c.Replies) < c.ReplyCount {
+		more := CommentCursor{ParentID: c.ID}
+		if len(c.Replies) > 0 {
+			more.AfterID = c.Replies[len(c.Replies)-1].ID
+		}
+		c.MoreReplies = more.Encode()
+	}
+}
+
+func (m *MockCommentStore) GetPath(ctx context.Context, commentID int64) ([]*Comment, error) {
+	var path []*Comment
+	for id := &commentID; id != nil; {
+		comment, err := m.GetByID(ctx, *id)
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, comment)
+		id = comment.ParentID
+	}
+	slices.Reverse(path)
+	return path, nil
+}
+
+func (m *MockCommentStore) GetDepth(ctx context.Context, commentID int64) (int, error) {
+	return 1, nil
+}
+
+// MockRoleStore knows the roles seeded by the migrations.
+type MockRoleStore struct{}
+
+func (m *MockRoleStore) GetByName(ctx context.Context, name string) (*Role, error) {
+	levels := map[string]int{"user": 1, "moderator": 2, "admin": 3}
+	level, ok := levels[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &Role{ID: int64(level), Name: name, Level: level}, nil
+}
+
+// MockVoteStore keeps the votes in memory by post or comment, then by voter.
+type MockVoteStore struct {
+	mu       sync.Mutex
+	Posts    map[int64]map[int64]int
+	Comments map[int64]map[int64]int
+}
+
+func (m *MockVoteStore) VotePost(ctx context.Context, postID, userID int64, value int) (*Vote, error) {
+	authorID, ok := mockPostAuthors[postID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Posts == nil {
+		m.Posts = make(map[int64]map[int64]int)
+	}
+	return vote(m.Posts, postID, userID, authorID, value), nil
+}
+
+func (m *MockVoteStore) VoteComment(ctx context.Context, commentID, userID int64, value int) (*Vote, error) {
+	comment, ok := mockComments[commentID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Comments == nil {
+		m.Comments = make(map[int64]map[int64]int)
+	}
+	return vote(m.Comments, commentID, userID, comment.UserID, value), nil
+}
+
+// vote replaces the vote of the user on the target and sums the score.
+func vote(votes map[int64]map[int64]int, targetID, userID, authorID int64, value int) *Vote {
+	if votes[targetID] == nil {
+		votes[targetID] = make(map[int64]int)
+	}
+	v := &Vote{UserID: userID, Value: value, Previous: votes[targetID][userID], AuthorID: authorID}
+	if value == 0 {
+		delete(votes[targetID], userID)
+	} else {
+		votes[targetID][userID] = value
+	}
+	for _, value := range votes[targetID] {
+		v.Score += value
+	}
+	return v
+}
+
+// MockNotificationStore keeps the created notifications in memory, they are
+// read an hour after mockEpoch.
+type MockNotificationStore struct {
+	mu      sync.Mutex
+	Created []Notification
+}
+
+// Create records the notification, notifications are created a second apart
+// so they list in insertion order.
+func (m *MockNotificationStore) Create(ctx context.Context, notification *Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	notification.ID = int64(len(m.Created) + 1)
+	notification.CreatedAt = mockEpoch.Add(time.Duration(notification.ID) * time.Second)
+	m.Created = append(m.Created, *notification)
+	return nil
+}
+
+func (m *MockNotificationStore) GetByUserID(ctx context.Context, userID int64, nq NotificationQuery) ([]Notification, string, error) {
+	var after *Cursor
+	if nq.Cursor != "" {
+		c, err := DecodeCursor(nq.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &c
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	notifications := []Notification{}
+	for _, n := range slices.Backward(m.Created) {
+		if n.UserID != userID || nq.Unread && n.ReadAt != nil {
+			continue
+		}
+		if after != nil && !n.CreatedAt.Before(after.CreatedAt) {
+			continue
+		}
+		if n.ActorID != nil {
+			n.ActorUsername = fmt.Sprintf("user%d", *n.ActorID)
+		}
+		notifications = append(notifications, n)
+	}
+
+	var next string
+	if len(notifications) > nq.Limit {
+		notifications = notifications[:nq.Limit]
+		last := notifications[len(notifications)-1]
+		next = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return notifications, next, nil
+}
+
+func (m *MockNotificationStore) CountUnread(ctx context.Context, userID int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var count int
+	for _, n := range m.Created {
+		if n.UserID == userID && n.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockNotificationStore) MarkRead(ctx context.Context, userID, notificationID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, n := range m.Created {
+		if n.ID == notificationID && n.UserID == userID {
+			if n.ReadAt == nil {
+				readAt := mockEpoch.Add(time.Hour)
+				m.Created[i].ReadAt = &readAt
+			}
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MockNotificationStore) MarkAllRead(ctx context.Context, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, n := range m.Created {
+		if n.UserID == userID && n.ReadAt == nil {
+			readAt := mockEpoch.Add(time.Hour)
+			m.Created[i].ReadAt = &readAt
+		}
+	}
+	return nil
+}
+
+// MockFollowerStore keeps the follows in memory as followed user ids by
+// follower.
+type MockFollowerStore struct {
+	mu      sync.Mutex
+	Follows map[int64][]int64
+}
+
+func (m *MockFollowerStore) Follow(ctx context.Context, followerID, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if slices.Contains(m.Follows[followerID], userID) {
+		return ErrFollowConflict
+	}
+	if m.Follows == nil {
+		m.Follows = map[int64][]int64{}
+	}
+	m.Follows[followerID] = append(m.Follows[followerID], userID)
+	return nil
+}
+
+func (m *MockFollowerStore) Unfollow(ctx context.Context, followerID, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Follows[followerID] = slices.DeleteFunc(m.Follows[followerID], func(id int64) bool { return id == userID })
+	return nil
+}
+
+func (m *MockFollowerStore) IsFollowing(ctx context.Context, followerID, userID int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Contains(m.Follows[followerID], userID), nil
+}
+
+// MockBookmarkStore keeps the bookmarks in memory, they are created a second
+// apart so they list in insertion order.
+type MockBookmarkStore struct {
+	mu        sync.Mutex
+	Bookmarks []Bookmark
+}
+
+func (m *MockBookmarkStore) Add(ctx context.Context, bookmark *Bookmark) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, b := range m.Bookmarks {
+		if b.UserID == bookmark.UserID && b.PostID == bookmark.PostID {
+			m.Bookmarks[i].Collection = bookmark.Collection
+			bookmark.ID, bookmark.CreatedAt = b.ID, b.CreatedAt
+			return nil
+		}
+	}
+	bookmark.ID = int64(len(m.Bookmarks) + 1)
+	bookmark.CreatedAt = mockEpoch.Add(time.Duration(bookmark.ID) * time.Second)
+	m.Bookmarks = append(m.Bookmarks, *bookmark)
+	return nil
+}
+
+func (m *MockBookmarkStore) Remove(ctx context.Context, userID, postID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, b := range m.Bookmarks {
+		if b.UserID == userID && b.PostID == postID {
+			m.Bookmarks = slices.Delete(m.Bookmarks, i, i+1)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MockBookmarkStore) Exists(ctx context.Context, userID, postID int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range m.Bookmarks {
+		if b.UserID == userID && b.PostID == postID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockBookmarkStore) GetByUserID(ctx context.Context, userID int64, bq BookmarkQuery) ([]Bookmark, string, error) {
+	var after *Cursor
+	if bq.Cursor != "" {
+		c, err := DecodeCursor(bq.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &c
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bookmarks := []Bookmark{}
+	for _, b := range slices.Backward(m.Bookmarks) {
+		if b.UserID != userID || (bq.Collection != "" && (b.Collection == nil || *b.Collection != bq.Collection)) {
+			continue
+		}
+		if after != nil && !b.CreatedAt.Before(after.CreatedAt) {
+			continue
+		}
+		bookmarks = append(bookmarks, b)
+	}
+
+	var next string
+	if len(bookmarks) > bq.Limit {
+		bookmarks = bookmarks[:bq.Limit]
+		last := bookmarks[len(bookmarks)-1]
+		next = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return bookmarks, next, nil
+}
+
+func (m *MockBookmarkStore) GetCollections(ctx context.Context, userID int64) ([]BookmarkCollection, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := map[string]int{}
+	for _, b := range m.Bookmarks {
+		if b.UserID == userID && b.Collection != nil {
+			counts[*b.Collection]++
+		}
+	}
+	collections := []BookmarkCollection{}
+	for name, count := range counts {
+		collections = append(collections, BookmarkCollection{Name: name, Count: count})
+	}
+	slices.SortFunc(collections, func(a, b BookmarkCollection) int { return strings.Compare(a.Name, b.Name) })
+	return collections, nil
+}
+
+// MockReactionStore keeps the reactions in memory. They are all created at the
+// same time so reactors only list in order of their reaction ids.
+type MockReactionStore struct {
+	mu        sync.Mutex
+	lastID    int64
+	reactions []mockReaction
+}
+
+type mockReaction struct {
+	id       int64
+	target   reactionTarget
+	targetID int64
+	userID   int64
+	reaction string
+}
+
+func (m *MockReactionStore) TogglePost(ctx context.Context, postID, userID int64, reaction string) (*ReactionToggle, error) {
+	return m.toggle(postReactionTarget, postID, userID, reaction), nil
+}
+
+func (m *MockReactionStore) ToggleComment(ctx context.Context, commentID, userID int64, reaction string) (*ReactionToggle, error) {
+	return m.toggle(commentReactionTarget, commentID, userID, reaction), nil
+}
+
+func (m *MockReactionStore) toggle(target reactionTarget, targetID, userID int64, reaction string) *ReactionToggle {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	toggle := &ReactionToggle{Reaction: reaction, Reacted: true}
+	for i, r := range m.reactions {
+		if r.target == target && r.targetID == targetID && r.userID == userID && r.reaction == reaction {
+			m.reactions = slices.Delete(m.reactions, i, i+1)
+			toggle.Reacted = false
+			break
+		}
+	}
+	if toggle.Reacted {
+		m.lastID++
+		m.reactions = append(m.reactions, mockReaction{
+			id:       m.lastID,
+			target:   target,
+			targetID: targetID,
+			userID:   userID,
+			reaction: reaction,
+		})
+	}
+	for _, r := range m.reactions {
+		if r.target == target && r.targetID == targetID && r.reaction == reaction {
+			toggle.Count++
+		}
+	}
+	return toggle
+}
+
+func (m *MockReactionStore) GetPostReactors(ctx context.Context, postID int64, rq ReactionQuery) ([]Reactor, string, error) {
+	return m.reactors(postReactionTarget, postID, rq)
+}
+
+func (m *MockReactionStore) GetCommentReactors(ctx context.Context, commentID int64, rq ReactionQuery) ([]Reactor, string, error) {
+	return m.reactors(commentReactionTarget, commentID, rq)
+}
+
+func (m *MockReactionStore) reactors(target reactionTarget, targetID int64, rq ReactionQuery) ([]Reactor, string, error) {
+	var after *Cursor
+	if rq.Cursor != "" {
+		c, err := DecodeCursor(rq.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &c
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	reactors := []Reactor{}
+	for _, r := range slices.Backward(m.reactions) {
+		if r.target != target || r.targetID != targetID || (rq.Reaction != "" && r.reaction != rq.Reaction) {
+			continue
+		}
+		if after != nil && (mockEpoch.After(after.CreatedAt) || (mockEpoch.Equal(after.CreatedAt) && r.id >= after.ID)) {
+			continue
+		}
+		reactors = append(reactors, Reactor{
+			UserID:    r.userID,
+			Username:  fmt.Sprintf("user%d", r.userID),
+			Reaction:  r.reaction,
+			CreatedAt: mockEpoch,
+			id:        r.id,
+		})
+	}
+
+	var next string
+	if len(reactors) > rq.Limit {
+		reactors = reactors[:rq.Limit]
+		last := reactors[len(reactors)-1]
+		next = Cursor{CreatedAt: last.CreatedAt, ID: last.id}.Encode()
+	}
+	return reactors, next, nil
+}
+
+// MockTagStore keeps the tags and the tags of posts in memory. It starts with
+// go, a synonym of golang, on posts 1 and 2 and rust on post 2.
+type MockTagStore struct {
+	mu       sync.Mutex
+	Tags     map[int64]*Tag
+	PostTags map[int64][]string
+}
+
+func NewMockTagStore() *MockTagStore {
+	return &MockTagStore{
+		Tags: map[int64]*Tag{
+			1: {ID: 1, Slug: "go", Synonyms: []string{"golang"}},
+			2: {ID: 2, Slug: "rust", Synonyms: []string{}},
+		},
+		PostTags: map[int64][]string{1: {"go"}, 2: {"go", "rust"}},
+	}
+}
+
+func (m *MockTagStore) GetAll(ctx context.Context, tq TagQuery) ([]Tag, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tags := []Tag{}
+	for _, t := range m.Tags {
+		if strings.HasPrefix(t.Slug, tq.Search) {
+			tags = append(tags, m.tag(t))
+		}
+	}
+	slices.SortFunc(tags, func(a, b Tag) int {
+		if a.UsageCount != b.UsageCount {
+			return b.UsageCount - a.UsageCount
+		}
+		return strings.Compare(a.Slug, b.Slug)
+	})
+	tags = tags[min(tq.Offset, len(tags)):]
+	return tags[:min(tq.Limit, len(tags))], nil
+}
+
+func (m *MockTagStore) GetBySlug(ctx context.Context, slug string) (*Tag, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.find(NormalizeTag(slug))
+	if t == nil {
+		return nil, ErrNotFound
+	}
+	tag := m.tag(t)
+	return &tag, nil
+}
+
+// find returns the tag with the slug or the synonym.
+func (m *MockTagStore) find(slug string) *Tag {
+	for _, t := range m.Tags {
+		if t.Slug == slug || slices.Contains(t.Synonyms, slug) {
+			return t
+		}
+	}
+	return nil
+}
+
+// tag copies the tag with its usage count.
+func (m *MockTagStore) tag(t *Tag) Tag {
+	tag := *t
+	tag.Synonyms = slices.Sorted(slices.Values(t.Synonyms))
+	for _, tags := range m.PostTags {
+		if slices.Contains(tags, t.Slug) {
+			tag.UsageCount++
+		}
+	}
+	return tag
+}
+
+func (m *MockTagStore) Canonical(ctx context.Context, slugs []string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	canonical := []string{}
+	for _, slug := range slugs {
+		if t := m.find(NormalizeTag(slug)); t != nil {
+			canonical = append(canonical, t.Slug)
+		}
+	}
+	return canonical, nil
+}
+
+func (m *MockTagStore) Update(ctx context.Context, tag *Tag) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.Tags[tag.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	t.Description = tag.Description
+	return nil
+}
+
+func (m *MockTagStore) AddSynonym(ctx context.Context, tagID int64, synonym string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.find(synonym) != nil {
+		return ErrConflict
+	}
+	m.Tags[tagID].Synonyms = append(m.Tags[tagID].Synonyms, synonym)
+	return nil
+}
+
+func (m *MockTagStore) RemoveSynonym(ctx context.Context, tagID int64, synonym string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.Tags[tagID]
+	i := slices.Index(t.Synonyms, synonym)
+	if i < 0 {
+		return ErrNotFound
+	}
+	t.Synonyms = slices.Delete(t.Synonyms, i, i+1)
+	return nil
+}
+
+func (m *MockTagStore) Rename(ctx context.Context, tag *Tag, slug string) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.Tags[tag.ID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if i := slices.Index(t.Synonyms, slug); i >= 0 {
+		t.Synonyms = slices.Delete(t.Synonyms, i, i+1)
+	} else if m.find(slug) != nil {
+		return nil, ErrConflict
+	}
+	retagged := m.replaceTag(t.Slug, slug)
+	t.Synonyms = append(t.Synonyms, t.Slug)
+	t.Slug = slug
+	tag.Slug = slug
+	return retagged, nil
+}
+
+func (m *MockTagStore) Merge(ctx context.Context, sourceID, targetID int64) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	source, target := m.Tags[sourceID], m.Tags[targetID]
+	if source == nil || target == nil {
+		return nil, ErrNotFound
+	}
+	retagged := m.replaceTag(source.Slug, target.Slug)
+	target.Synonyms = append(target.Synonyms, source.Synonyms...)
+	target.Synonyms = append(target.Synonyms, source.Slug)
+	if target.Description == "" {
+		target.Description = source.Description
+	}
+	delete(m.Tags, sourceID)
+	return retagged, nil
+}
+
+// replaceTag replaces the tag on the posts using it, once per post.
+func (m *MockTagStore) replaceTag(old, slug string) []int64 {
+	var retagged []int64
+	for postID, tags := range m.PostTags {
+		i := slices.Index(tags, old)
+		if i < 0 {
+			continue
+		}
+		if slices.Contains(tags, slug) {
+			tags = slices.Delete(tags, i, i+1)
+		} else {
+			tags[i] = slug
+		}
+		m.PostTags[postID] = tags
+		retagged = append(retagged, postID)
+	}
+	slices.Sort(retagged)
+	return retagged
+}
+
+func (m *MockTagStore) Subscribe(ctx context.Context, userID, tagID int64, kind string) error {
+	return nil
+}
+
+func (m *MockTagStore) Unsubscribe(ctx context.Context, userID, tagID int64, kind string) error {
+	return nil
+}
+
+func (m *MockTagStore) GetSubscriptions(ctx context.Context, userID int64) ([]TagSubscription, error) {
+	return []TagSubscription{}, nil
+}
+
+// MockSearchStore matches the query against the titles of mockFeed, newest
+// first. Query is the last search.
+type MockSearchStore struct {
+	mu    sync.Mutex
+	Query SearchQuery
+}
+
+func (m *MockSearchStore) Search(ctx context.Context, sq SearchQuery) ([]SearchResult, error) {
+	m.mu.Lock()
+	m.Query = sq
+	m.mu.Unlock()
+
+	results := []SearchResult{}
+	for _, p := range slices.Backward(mockFeed) {
+		if strings.Contains(strings.ToLower(p.Title), strings.ToLower(sq.Query)) {
+			results = append(results, SearchResult{ID: p.ID, Title: p.Title, CreatedAt: p.CreatedAt, Highlight: p.Title})
+		}
+	}
+	results = results[min(sq.Offset, len(results)):]
+	return results[:min(sq.Limit, len(results))], nil
+}
+
+func (m *MockSearchStore) GetDocuments(ctx context.Context, postIDs []int64) ([]SearchDocument, error) {
+	return []SearchDocument{}, nil
+}
+
+func (m *MockSearchStore) ListDocuments(ctx context.Context, afterID int64, limit int) ([]SearchDocument, error) {
+	return []SearchDocument{}, nil
+}
+
+// MockMentionStore keeps the mentions in memory. The users it knows are user1
+// and user2, of ids 1 and 2. Notified records the sources each user was
+// notified of.
+type MockMentionStore struct {
+	mu       sync.Mutex
+	Mentions []Mention
+	Notified map[int64][]MentionSource
+}
+
+func (m *MockMentionStore) Set(ctx context.Context, src MentionSource, usernames []string) ([]Mention, []Mention, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Notified == nil {
+		m.Notified = map[int64][]MentionSource{}
+	}
+
+	sameSource := func(postID int64, commentID *int64) bool {
+		return postID == src.PostID && (commentID == nil) == (src.CommentID == nil) && (commentID == nil || *commentID == *src.CommentID)
+	}
+	m.Mentions = slices.DeleteFunc(m.Mentions, func(mention Mention) bool {
+		return sameSource(mention.PostID, mention.CommentID)
+	})
+
+	mentions := []Mention{}
+	var added []Mention
+	for _, username := range usernames {
+		userID, ok := map[string]int64{"user1": 1, "user2": 2}[strings.ToLower(username)]
+		if !ok {
+			continue
+		}
+		mention := Mention{UserID: userID, Username: fmt.Sprintf("user%d", userID), PostID: src.PostID, CommentID: src.CommentID}
+		mentions = append(mentions, mention)
+
+		notified := slices.ContainsFunc(m.Notified[userID], func(n MentionSource) bool {
+			return sameSource(n.PostID, n.CommentID)
+		})
+		if !notified {
+			m.Notified[userID] = append(m.Notified[userID], src)
+			added = append(added, mention)
+		}
+	}
+	m.Mentions = append(m.Mentions, mentions...)
+	return mentions, added, nil
+}
+
+func (m *MockMentionStore) GetByPostID(ctx context.Context, postID int64) ([]Mention, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mentions := []Mention{}
+	for _, mention := range m.Mentions {
+		if mention.PostID == postID {
+			mentions = append(mentions, mention)
+		}
+	}
+	return mentions, nil
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Notification tells the user about an event caused by the actor, on the post
+// or the comment when set. Message is filled from the type when listing.
+type Notification struct {
+	ID            int64      `json:"id"`
+	UserID        int64      `json:"user_id"`
+	Type          string     `json:"type"`
+	Message       string     `json:"message"`
+	ActorID       *int64     `json:"actor_id,omitempty"`
+	ActorUsername string     `json:"actor_username,omitempty"`
+	PostID        *int64     `json:"post_id,omitempty"`
+	CommentID     *int64     `json:"comment_id,omitempty"`
+	ReadAt        *time.Time `json:"read_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// UnreadNotifications counts the unread notifications of a user.
+type UnreadNotifications struct {
+	Unread int `json:"unread"`
+}
+
+type NotificationQuery struct {
+	Limit  int    `json:"limit" validate:"gte=1,lte=50"`
+	Cursor string `json:"cursor"`
+	// Unread only lists the notifications not read yet
+	Unread bool `json:"unread"`
+}
+
+func (nq NotificationQuery) Parse(r *http.Request) (NotificationQuery, error) {
+	q := r.URL.Query()
+	limit := q.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return nq, err
+		}
+		nq.Limit = l
+	}
+
+	unread := q.Get("unread")
+	if unread != "" {
+		u, err := strconv.ParseBool(unread)
+		if err != nil {
+			return nq, err
+		}
+		nq.Unread = u
+	}
+
+	nq.Cursor = q.Get("cursor")
+	return nq, nil
+}
+
+type PostgresNotificationStore struct {
+	db *sql.DB
+}
+
+// Create stores the notification unless the same one is still unread.
+func (s *PostgresNotificationStore) Create(ctx context.Context, n *Notification) error {
+	query := `
+	INSERT INTO notifications (user_id, type, actor_id, post_id, comment_id)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT DO NOTHING
+	RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	err := s.db.QueryRowContext(ctx, query, n.UserID, n.Type, n.ActorID, n.PostID, n.CommentID).Scan(&n.ID, &n.CreatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	return nil
+}
+
+// GetByUserID lists the notifications of the user newest first.
+func (s *PostgresNotificationStore) GetByUserID(ctx context.Context, userID int64, nq NotificationQuery) ([]Notification, string, error) {
+	var afterTime *time.Time
+	var afterID int64
+	if nq.Cursor != "" {
+		c, err := DecodeCursor(nq.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		afterTime, afterID = &c.CreatedAt, c.ID
+	}
+
+	query := `
+	SELECT n.id, n.user_id, n.type, n.actor_id, COALESCE(u.username, ''), n.post_id, n.comment_id, n.read_at, n.created_at
+	FROM notifications n
+	LEFT JOIN users u ON u.id = n.actor_id
+	WHERE n.user_id = $1
+	    AND (NOT $2 OR n.read_at IS NULL)
+	    AND ($3::timestamptz IS NULL OR (n.created_at, n.id) < ($3, $4))
+	ORDER BY n.created_at DESC, n.id DESC
+	LIMIT $5`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query, userID, nq.Unread, afterTime, afterID, nq.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.ActorID, &n.ActorUsername, &n.PostID, &n.CommentID, &n.ReadAt, &n.CreatedAt)
+		if err != nil {
+			return nil, "", err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(notifications) > nq.Limit {
+		notifications = notifications[:nq.Limit]
+		last := notifications[len(notifications)-1]
+		next = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return notifications, next, nil
+}
+
+func (s *PostgresNotificationStore) CountUnread(ctx context.Context, userID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var count int
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks a notification of the user as read, it is a no-op when the
+// notification was already read.
+func (s *PostgresNotificationStore) MarkRead(ctx context.Context, userID, notificationID int64) error {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, notificationID, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks the unread notifications of the user as read.
+func (s *PostgresNotificationStore) MarkAllRead(ctx context.Context, userID int64) error {
+	query := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, userID)
+	return err
+}
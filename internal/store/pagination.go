@@ -1,20 +1,34 @@
 package store
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 )
 
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 type PagintatedFeedQuery struct {
 	Limit  int      `json:"limit" validate:"gte=1,lte=20"`
 	Offset int      `json:"offset" validate:"gte=0"`
-	Sort   string   `json:"sort" validate:"oneof=asc desc"`
+	Sort   string   `json:"sort" validate:"oneof=asc desc top updated comments ranked"`
 	Tags   []string `json:"tags" validate:"max=5"`
 	Search string   `json:"search" validate:"max=100"`
-	Since  string   `json:"since"`
-	Until  string   `json:"until"`
+	// Cursor resumes the listing after the last post of the previous page, it
+	// replaces Offset for the sorts by creation time
+	Cursor string `json:"cursor"`
+	// Since and Until bound the creation time of the listed posts, Until is
+	// exclusive
+	Since *time.Time `json:"since"`
+	Until *time.Time `json:"until"`
+	// Answered filters questions by whether they have an accepted answer, nil disables the filter
+	Answered *bool `json:"answered"`
+	// Bountied only keeps questions with an open bounty
+	Bountied bool `json:"bountied"`
 }
 
 func (fq PagintatedFeedQuery) Parse(r *http.Request) (PagintatedFeedQuery, error) {
@@ -23,7 +37,7 @@ func (fq PagintatedFeedQuery) Parse(r *http.Request) (PagintatedFeedQuery, error
 	if limit != "" {
 		l, err := strconv.Atoi(limit)
 		if err != nil {
-			return fq, nil
+			return fq, err
 		}
 		fq.Limit = l
 	}
@@ -31,7 +45,7 @@ func (fq PagintatedFeedQuery) Parse(r *http.Request) (PagintatedFeedQuery, error
 	if offset != "" {
 		l, err := strconv.Atoi(offset)
 		if err != nil {
-			return fq, nil
+			return fq, err
 		}
 		fq.Offset = l
 	}
@@ -41,6 +55,8 @@ func (fq PagintatedFeedQuery) Parse(r *http.Request) (PagintatedFeedQuery, error
 		fq.Sort = sort
 	}
 
+	fq.Cursor = q.Get("cursor")
+
 	tags := q.Get("tags")
 	if tags != "" {
 		fq.Tags = strings.Split(tags, ",")
@@ -51,23 +67,240 @@ func (fq PagintatedFeedQuery) Parse(r *http.Request) (PagintatedFeedQuery, error
 		fq.Search = search
 	}
 
+	answered := q.Get("answered")
+	if answered != "" {
+		a, err := strconv.ParseBool(answered)
+		if err != nil {
+			return fq, err
+		}
+		fq.Answered = &a
+	}
+
+	bountied := q.Get("bountied")
+	if bountied != "" {
+		b, err := strconv.ParseBool(bountied)
+		if err != nil {
+			return fq, err
+		}
+		fq.Bountied = b
+	}
+
 	since := q.Get("since")
 	if since != "" {
-		fq.Since = parseTime(since)
+		t, err := parseTime(since)
+		if err != nil {
+			return fq, fmt.Errorf("invalid since: %w", err)
+		}
+		fq.Since = &t
 	}
 
 	until := q.Get("until")
 	if until != "" {
-		fq.Until = parseTime(until)
+		t, err := parseTime(until)
+		if err != nil {
+			return fq, fmt.Errorf("invalid until: %w", err)
+		}
+		fq.Until = &t
+	}
+
+	if fq.Since != nil && fq.Until != nil && !fq.Until.After(*fq.Since) {
+		return fq, errors.New("until must be after since")
 	}
 
 	return fq, nil
 }
 
-func parseTime(s string) string {
-	t, err := time.Parse(time.DateTime, s)
+// ExploreQuery filters the explore feed. Window bounds the top sort to the
+// posts created during the last day, week, month or year.
+type ExploreQuery struct {
+	Limit  int      `json:"limit" validate:"gte=1,lte=20"`
+	Offset int      `json:"offset" validate:"gte=0"`
+	Sort   string   `json:"sort" validate:"oneof=latest top trending"`
+	Window string   `json:"window" validate:"oneof=day week month year all"`
+	Tags   []string `json:"tags" validate:"max=5"`
+}
+
+func (eq ExploreQuery) Parse(r *http.Request) (ExploreQuery, error) {
+	q := r.URL.Query()
+	limit := q.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return eq, err
+		}
+		eq.Limit = l
+	}
+
+	offset := q.Get("offset")
+	if offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil {
+			return eq, err
+		}
+		eq.Offset = o
+	}
+
+	if sort := q.Get("sort"); sort != "" {
+		eq.Sort = sort
+	}
+
+	if window := q.Get("window"); window != "" {
+		eq.Window = window
+	}
+
+	if tags := q.Get("tags"); tags != "" {
+		eq.Tags = strings.Split(tags, ",")
+	}
+	return eq, nil
+}
+
+// Since returns the start of the window, the zero time for all.
+func (eq ExploreQuery) Since(now time.Time) time.Time {
+	switch eq.Window {
+	case "day":
+		return now.AddDate(0, 0, -1)
+	case "week":
+		return now.AddDate(0, 0, -7)
+	case "month":
+		return now.AddDate(0, -1, 0)
+	case "year":
+		return now.AddDate(-1, 0, 0)
+	default:
+		return time.Time{}
+	}
+}
+
+// CommentQuery pages through the comment tree of a post. Limit bounds the
+// comments listed at the top level, Replies the replies listed under each
+// comment and Depth the levels of the tree listed, 1 listing no reply. Cursor continues a
+// listing: the next top-level page or more replies of a comment.
+type CommentQuery struct {
+	Limit   int    `json:"limit" validate:"gte=1,lte=50"`
+	Replies int    `json:"replies" validate:"gte=0,lte=20"`
+	Depth   int    `json:"depth" validate:"gte=1,lte=10"`
+	Sort    string `json:"sort" validate:"oneof=oldest newest top"`
+	Cursor  string `json:"cursor"`
+}
+
+func (cq CommentQuery) Parse(r *http.Request) (CommentQuery, error) {
+	q := r.URL.Query()
+	limit := q.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return cq, err
+		}
+		cq.Limit = l
+	}
+
+	replies := q.Get("replies")
+	if replies != "" {
+		n, err := strconv.Atoi(replies)
+		if err != nil {
+			return cq, err
+		}
+		cq.Replies = n
+	}
+
+	depth := q.Get("depth")
+	if depth != "" {
+		d, err := strconv.Atoi(depth)
+		if err != nil {
+			return cq, err
+		}
+		cq.Depth = d
+	}
+
+	if sort := q.Get("sort"); sort != "" {
+		cq.Sort = sort
+	}
+
+	cq.Cursor = q.Get("cursor")
+	return cq, nil
+}
+
+// CommentCursor is a position among the replies to a comment, or among the
+// top-level comments when ParentID is 0: the listing resumes after the sibling
+// AfterID, from the start when it is 0. Being keyed on a comment rather than
+// an offset, pages do not shift when siblings are added. It is handed to
+// clients as an opaque string.
+type CommentCursor struct {
+	ParentID int64
+	AfterID  int64
+}
+
+func (c CommentCursor) Encode() string {
+	raw := fmt.Sprintf("%d,%d", c.ParentID, c.AfterID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCommentCursor(s string) (CommentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
 	if err != nil {
-		return ""
+		return CommentCursor{}, ErrInvalidCursor
+	}
+	parent, after, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return CommentCursor{}, ErrInvalidCursor
+	}
+	var c CommentCursor
+	if c.ParentID, err = strconv.ParseInt(parent, 10, 64); err != nil || c.ParentID < 0 {
+		return CommentCursor{}, ErrInvalidCursor
+	}
+	if c.AfterID, err = strconv.ParseInt(after, 10, 64); err != nil || c.AfterID < 0 {
+		return CommentCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Keyset reports whether the listing is sorted by creation time and can be
+// paginated with a cursor.
+func (fq PagintatedFeedQuery) Keyset() bool {
+	return fq.Sort == "asc" || fq.Sort == "desc"
+}
+
+var errInvalidTime = fmt.Errorf("expected RFC 3339 (%s) or %q", time.RFC3339, time.DateTime)
+
+// parseTime accepts RFC 3339 timestamps as well as time.DateTime, the latter
+// being read as UTC.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateTime, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errInvalidTime
+}
+
+// Cursor is a keyset position in a listing ordered by (created_at, id), it is
+// handed to clients as an opaque string.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+func (c Cursor) Encode() string {
+	raw := fmt.Sprintf("%d,%d", c.CreatedAt.UnixNano(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	c := Cursor{CreatedAt: time.Unix(0, n)}
+	if c.ID, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return Cursor{}, ErrInvalidCursor
 	}
-	return t.Format(time.DateTime)
+	return c, nil
 }
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func encodeRaw(raw string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func TestCursor(t *testing.T) {
+	c := Cursor{CreatedAt: time.Date(2026, 1, 10, 12, 30, 0, 123, time.UTC), ID: 42}
+	got, err := DecodeCursor(c.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(c.CreatedAt) || got.ID != c.ID {
+		t.Errorf("got %+v, want %+v", got, c)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"empty", ""},
+		{"not base64", "not a cursor!"},
+		{"standard base64 padding", base64.StdEncoding.EncodeToString([]byte("10,2"))},
+		{"missing separator", encodeRaw("12")},
+		{"invalid time", encodeRaw("yesterday,2")},
+		{"invalid id", encodeRaw("1,two")},
+		{"extra field", encodeRaw("1,2,3")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.cursor); err != ErrInvalidCursor {
+				t.Errorf("got %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2026-01-10T12:30:00Z", want: time.Date(2026, 1, 10, 12, 30, 0, 0, time.UTC)},
+		{value: "2026-01-10T12:30:00+02:00", want: time.Date(2026, 1, 10, 10, 30, 0, 0, time.UTC)},
+		{value: "2026-01-10 12:30:00", want: time.Date(2026, 1, 10, 12, 30, 0, 0, time.UTC)},
+		{value: "2026-01-10", wantErr: true},
+		{value: "10/01/2026", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseTime(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentCursor(t *testing.T) {
+	for _, c := range []CommentCursor{{}, {ParentID: 7}, {ParentID: 7, AfterID: 12}} {
+		got, err := DecodeCommentCursor(c.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c {
+			t.Errorf("got %+v, want %+v", got, c)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"empty", ""},
+		{"not base64", "not a cursor!"},
+		{"missing separator", encodeRaw("7")},
+		{"negative parent", encodeRaw("-1,0")},
+		{"negative comment", encodeRaw("7,-3")},
+		{"invalid comment", encodeRaw("7,last")},
+		{"fractional comment", encodeRaw("7,1.5")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCommentCursor(tt.cursor); err != ErrInvalidCursor {
+				t.Errorf("got %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
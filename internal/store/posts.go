@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/lib/pq"
+	"github.com/theluminousartemis/inkspire/internal/ranking"
 )
 
 type PostUser struct {
@@ -16,59 +17,110 @@ type PostUser struct {
 }
 
 type Post struct {
-	ID        int64      `json:"id"`
-	Title     string     `json:"title"`
-	Content   string     `json:"content"`
-	UserID    int64      `json:"user_id"`
-	Tags      []string   `json:"tags"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	Comments  []*Comment `json:"comments"`
-	Version   int        `json:"version"`
-	User      PostUser   `json:"user"`
+	ID                int64           `json:"id"`
+	Title             string          `json:"title"`
+	Content           string          `json:"content"`
+	ContentHTML       string          `json:"content_html,omitempty"`
+	UserID            int64           `json:"user_id"`
+	Tags              []string        `json:"tags"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	Comments          []*Comment      `json:"comments"`
+	CommentsCursor    string          `json:"comments_next_cursor,omitempty"`
+	Version           int             `json:"version"`
+	Score             int             `json:"score"`
+	AcceptedCommentID *int64          `json:"accepted_comment_id"`
+	Banner            *PostBanner     `json:"banner,omitempty"`
+	Bookmarked        bool            `json:"bookmarked"`
+	Reactions         map[string]int  `json:"reactions"`
+	User              PostUser        `json:"user"`
+	Related           []RelatedPost   `json:"related,omitempty"`
+	CommentSettings   CommentSettings `json:"comment_settings"`
+}
+
+// CommentSettings restricts commenting on a post. MaxDepth bounds the nesting
+// of replies, top-level comments being at depth 1, and nil falls back to the
+// site-wide limit. FollowersOnly only lets the followers of the author comment.
+type CommentSettings struct {
+	Locked        bool `json:"locked"`
+	MaxDepth      *int `json:"max_depth"`
+	FollowersOnly bool `json:"followers_only"`
+}
+
+// Closed reports whether the post was closed and no longer takes answers.
+func (p *Post) Closed() bool {
+	return p.Banner != nil
 }
 
 type SwaggerPostResponseSuccess struct {
-	ID        int64                    `json:"id"`
-	Title     string                   `json:"title"`
-	Content   string                   `json:"content"`
-	UserID    int64                    `json:"user_id"`
-	Tags      []string                 `json:"tags"`
-	CreatedAt time.Time                `json:"created_at"`
-	UpdatedAt time.Time                `json:"updated_at"`
-	Comments  []SwaggerCommentResponse `json:"comments"`
-	Version   int                      `json:"version"`
-	User      PostUser                 `json:"user"`
+	ID                int64                    `json:"id"`
+	Title             string                   `json:"title"`
+	Content           string                   `json:"content"`
+	ContentHTML       string                   `json:"content_html,omitempty"`
+	UserID            int64                    `json:"user_id"`
+	Tags              []string                 `json:"tags"`
+	CreatedAt         time.Time                `json:"created_at"`
+	UpdatedAt         time.Time                `json:"updated_at"`
+	Comments          []SwaggerCommentResponse `json:"comments"`
+	CommentsCursor    string                   `json:"comments_next_cursor,omitempty"`
+	Version           int                      `json:"version"`
+	Score             int                      `json:"score"`
+	AcceptedCommentID *int64                   `json:"accepted_comment_id"`
+	Banner            *PostBanner              `json:"banner,omitempty"`
+	Bookmarked        bool                     `json:"bookmarked"`
+	Reactions         map[string]int           `json:"reactions"`
+	User              PostUser                 `json:"user"`
 }
 
 type PostWithMetadata struct {
 	Post
-	CommentCount int `json:"comment_count"`
+	CommentCount    int        `json:"comment_count"`
+	BookmarkCount   int        `json:"bookmark_count"`
+	BountyAmount    *int       `json:"bounty_amount,omitempty"`
+	BountyExpiresAt *time.Time `json:"bounty_expires_at,omitempty"`
+}
+
+// PostScore is the ranking of a post in a listing such as trending.
+type PostScore struct {
+	PostID int64   `json:"post_id"`
+	Score  float64 `json:"score"`
 }
 
 type PostgresPostStore struct {
 	db *sql.DB
 }
 
+// Create inserts the post with its tags mapped to canonical slugs, tags used
+// for the first time are created.
 func (s *PostgresPostStore) Create(ctx context.Context, post *Post) error {
-	query := `INSERT INTO posts (title, content, user_id, tags) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
-	row := s.db.QueryRowContext(ctx, query, post.Title, post.Content, post.UserID, pq.Array(post.Tags))
-	err := row.Scan(&post.ID, &post.CreatedAt, &post.UpdatedAt)
-	if err != nil {
-		return err
-	}
-	return nil
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		tags, err := resolveTags(ctx, tx, post.Tags)
+		if err != nil {
+			return err
+		}
+		post.Tags = tags
+
+		query := `INSERT INTO posts (title, content, user_id, tags) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at`
+		row := tx.QueryRowContext(ctx, query, post.Title, post.Content, post.UserID, pq.Array(post.Tags))
+		if err := row.Scan(&post.ID, &post.CreatedAt, &post.UpdatedAt); err != nil {
+			return err
+		}
+		return refreshTagCounts(ctx, tx, post.Tags)
+	})
 }
 
 func (s *PostgresPostStore) GetByID(ctx context.Context, postID int64) (*Post, error) {
-	query := `SELECT p.id, p.title, p.content, p.user_id, p.created_at, p.updated_at, p.tags, p.version, u.id, u.username FROM posts p JOIN users u ON p.user_id = u.id WHERE p.id = $1`
+	query := `SELECT p.id, p.title, p.content, p.user_id, p.created_at, p.updated_at, p.tags, p.version, p.score, p.accepted_comment_id, p.closed_reason, p.duplicate_of, p.closed_at, ` +
+		reactionCountsQuery(postReactionTarget, "p") + `, u.id, u.username, p.comments_locked, p.comments_max_depth, p.comments_followers_only FROM posts p JOIN users u ON p.user_id = u.id WHERE p.id = $1`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 	row := s.db.QueryRowContext(ctx, query, postID)
 	post := &Post{}
-	err := row.Scan(&post.ID, &post.Title, &post.Content, &post.UserID, &post.CreatedAt, &post.UpdatedAt, pq.Array(&post.Tags), &post.Version, &post.User.ID, &post.User.Username)
+	var closure postClosure
+	err := row.Scan(&post.ID, &post.Title, &post.Content, &post.UserID, &post.CreatedAt, &post.UpdatedAt, pq.Array(&post.Tags), &post.Version, &post.Score, &post.AcceptedCommentID, &closure.reason, &closure.duplicateOf, &closure.closedAt, (*reactionCounts)(&post.Reactions), &post.User.ID, &post.User.Username, &post.CommentSettings.Locked, &post.CommentSettings.MaxDepth, &post.CommentSettings.FollowersOnly)
 	if err != nil {
 		switch err {
 		case sql.ErrNoRows:
@@ -77,14 +129,17 @@ func (s *PostgresPostStore) GetByID(ctx context.Context, postID int64) (*Post, e
 			return nil, err
 		}
 	}
+	post.Banner = closure.banner()
 	return post, nil
 }
 
-func (s *PostgresPostStore) Delete(ctx context.Context, postID int64) error {
-	query := `UPDATE posts SET title = $1, content = $1, deleted = true WHERE id = $2`
+// UpdateCommentSettings replaces the restrictions on commenting on the post.
+func (s *PostgresPostStore) UpdateCommentSettings(ctx context.Context, postID int64, settings CommentSettings) error {
+	query := `UPDATE posts SET comments_locked = $1, comments_max_depth = $2, comments_followers_only = $3 WHERE id = $4`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
-	res, err := s.db.ExecContext(ctx, query, DeletedContent, postID)
+
+	res, err := s.db.ExecContext(ctx, query, settings.Locked, settings.MaxDepth, settings.FollowersOnly, postID)
 	if err != nil {
 		return err
 	}
@@ -98,26 +153,350 @@ func (s *PostgresPostStore) Delete(ctx context.Context, postID int64) error {
 	return nil
 }
 
+func (s *PostgresPostStore) Delete(ctx context.Context, postID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var tags []string
+		query := `UPDATE posts SET title = $1, content = $1, deleted = true WHERE id = $2 RETURNING tags`
+		if err := tx.QueryRowContext(ctx, query, DeletedContent, postID).Scan(pq.Array(&tags)); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		return refreshTagCounts(ctx, tx, tags)
+	})
+}
+
+// Update saves the title, content and tags of the post if its version still
+// matches, and recounts the usage of the tags that were added or removed.
 func (s *PostgresPostStore) Update(ctx context.Context, post *Post) error {
-	query := `UPDATE posts SET title = $1, content = $2, version = version+1 WHERE id = $3 AND VERSION=$4 RETURNING VERSION`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		tags, err := resolveTags(ctx, tx, post.Tags)
+		if err != nil {
+			return err
+		}
+		post.Tags = tags
+
+		var previous []string
+		query := `UPDATE posts p SET title = $1, content = $2, tags = $3, version = p.version+1, updated_at = NOW()
+		FROM (SELECT id, tags FROM posts WHERE id = $4 FOR UPDATE) old
+		WHERE p.id = old.id AND p.version = $5
+		RETURNING p.version, p.updated_at, old.tags`
+		err = tx.QueryRowContext(ctx, query, post.Title, post.Content, pq.Array(post.Tags), post.ID, post.Version).Scan(&post.Version, &post.UpdatedAt, pq.Array(&previous))
+		log.Printf("Updating post ID %d with title=%s content=%s", post.ID, post.Title, post.Content)
 
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			default:
+				return err
+			}
+		}
+		return refreshTagCounts(ctx, tx, append(previous, post.Tags...))
+	})
+}
+
+// SetAcceptedAnswer marks the comment as the accepted answer of the post, a nil
+// commentID clears it. The reputation of the previous and new answer authors is
+// adjusted in the same transaction and the recorded events are returned.
+func (s *PostgresPostStore) SetAcceptedAnswer(ctx context.Context, postID int64, commentID *int64) ([]ReputationEvent, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
-	err := s.db.QueryRowContext(ctx, query, post.Title, post.Content, post.ID, post.Version).Scan(&post.Version)
-	log.Printf("Updating post ID %d with title=%s content=%s", post.ID, post.Title, post.Content)
 
-	if err != nil {
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			return ErrNotFound
-		default:
+	var events []ReputationEvent
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var authorID int64
+		var previous *int64
+		query := `SELECT user_id, accepted_comment_id FROM posts WHERE id = $1 FOR UPDATE`
+		if err := tx.QueryRowContext(ctx, query, postID).Scan(&authorID, &previous); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
 			return err
 		}
+
+		if previous == commentID || (previous != nil && commentID != nil && *previous == *commentID) {
+			return nil
+		}
+
+		query = `UPDATE posts SET accepted_comment_id = $1 WHERE id = $2`
+		if _, err := tx.ExecContext(ctx, query, commentID, postID); err != nil {
+			return err
+		}
+
+		if previous != nil {
+			e, err := acceptedAnswerEvent(ctx, tx, postID, *previous, authorID)
+			if err != nil {
+				return err
+			}
+			if e.UserID != authorID {
+				events = append(events, e.reversal())
+			}
+		}
+		if commentID != nil {
+			e, err := acceptedAnswerEvent(ctx, tx, postID, *commentID, authorID)
+			if err != nil {
+				return err
+			}
+			//users do not earn reputation from their own answers
+			if e.UserID != authorID {
+				events = append(events, e)
+			}
+		}
+		return recordReputation(ctx, tx, events...)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return events, nil
+}
+
+func acceptedAnswerEvent(ctx context.Context, tx *sql.Tx, postID, commentID, acceptedBy int64) (ReputationEvent, error) {
+	var answerAuthorID int64
+	query := `SELECT user_id FROM comments WHERE id = $1`
+	if err := tx.QueryRowContext(ctx, query, commentID).Scan(&answerAuthorID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ReputationEvent{}, ErrNotFound
+		}
+		return ReputationEvent{}, err
+	}
+	e := newReputationEvent(answerAuthorID, acceptedBy, ReputationAcceptedAnswer)
+	e.PostID = &postID
+	e.CommentID = &commentID
+	return e, nil
+}
+
+// GetUserFeed lists the posts of the user, of the people they follow and the
+// posts tagged with a tag they follow. Posts of others tagged with a tag the
+// user ignores are left out.
+func (s *PostgresPostStore) GetUserFeed(ctx context.Context, userID int64, fq PagintatedFeedQuery) ([]PostWithMetadata, string, error) {
+	return s.pagedFeed(ctx, userFeedScope, userID, fq)
+}
+
+// userFeedScope keeps the live posts of $1, of the users they follow and of
+// the tags they follow, leaving out the tags they ignore.
+var userFeedScope = `p.deleted IS NOT TRUE
+	    AND (p.user_id = $1
+	        OR p.user_id IN (SELECT user_id FROM followers WHERE follower_id = $1)
+	        OR COALESCE(p.tags, '{}') && ` + subscribedTagsQuery(TagFollow) + `)
+	    AND (p.user_id = $1 OR NOT COALESCE(p.tags, '{}') && ` + subscribedTagsQuery(TagIgnore) + `)`
+
+// GetRankedUserFeed ranks up to candidates of the newest posts of the user feed
+// with the weights and returns the requested page of the ranking.
+func (s *PostgresPostStore) GetRankedUserFeed(ctx context.Context, userID int64, fq PagintatedFeedQuery, weights ranking.Weights, candidates int) ([]PostWithMetadata, error) {
+	page := fq
+	fq.Sort, fq.Limit, fq.Offset, fq.Cursor = "desc", candidates, 0, ""
+	posts, err := s.feed(ctx, userFeedScope, feedOrderBy(fq.Sort), userID, fq)
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make([]int64, 0, len(posts))
+	for _, p := range posts {
+		authors = append(authors, p.UserID)
+	}
+	affinity, err := s.authorAffinity(ctx, userID, authors)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]PostWithMetadata, len(posts))
+	ranked := make([]ranking.Candidate, 0, len(posts))
+	for _, p := range posts {
+		byID[p.ID] = p
+		ranked = append(ranked, ranking.Candidate{
+			ID:        p.ID,
+			CreatedAt: p.CreatedAt,
+			Votes:     p.Score,
+			Comments:  p.CommentCount,
+			Affinity:  affinity[p.UserID],
+		})
+	}
+	ranked = weights.Rank(ranked, time.Now())
+
+	start := min(page.Offset, len(ranked))
+	end := min(start+page.Limit, len(ranked))
+	feed := make([]PostWithMetadata, 0, end-start)
+	for _, c := range ranked[start:end] {
+		feed = append(feed, byID[c.ID])
+	}
+	return feed, nil
+}
+
+// authorAffinity counts the interactions of the user with the posts and
+// comments of each author: upvotes, comments, reactions and bookmarks. Their
+// own posts are left out.
+func (s *PostgresPostStore) authorAffinity(ctx context.Context, userID int64, authorIDs []int64) (map[int64]int, error) {
+	query := `
+	SELECT author_id, COUNT(*) FROM (
+	    SELECT p.user_id AS author_id FROM post_votes v JOIN posts p ON p.id = v.post_id
+	    WHERE v.user_id = $1 AND v.value = 1
+	    UNION ALL
+	    SELECT c.user_id FROM comment_votes v JOIN comments c ON c.id = v.comment_id
+	    WHERE v.user_id = $1 AND v.value = 1
+	    UNION ALL
+	    SELECT p.user_id FROM comments c JOIN posts p ON p.id = c.post_id
+	    WHERE c.user_id = $1
+	    UNION ALL
+	    SELECT p.user_id FROM post_reactions r JOIN posts p ON p.id = r.post_id
+	    WHERE r.user_id = $1
+	    UNION ALL
+	    SELECT p.user_id FROM bookmarks b JOIN posts p ON p.id = b.post_id
+	    WHERE b.user_id = $1
+	) interactions
+	WHERE author_id = ANY($2) AND author_id <> $1
+	GROUP BY author_id
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID, pq.Array(authorIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	affinity := make(map[int64]int)
+	for rows.Next() {
+		var authorID int64
+		var count int
+		if err := rows.Scan(&authorID, &count); err != nil {
+			return nil, err
+		}
+		affinity[authorID] = count
+	}
+	return affinity, rows.Err()
+}
+
+// subscribedTagsQuery selects the slugs of the tags $1 subscribed to with the
+// given kind as an array.
+func subscribedTagsQuery(kind string) string {
+	return `ARRAY(SELECT t.slug FROM tag_subscriptions ts JOIN tags t ON t.id = ts.tag_id
+	        WHERE ts.user_id = $1 AND ts.kind = '` + kind + `')::varchar[]`
+}
+
+// GetTagFeed lists the live posts tagged with the slug, userID is the viewer
+// and only used to flag their bookmarks.
+func (s *PostgresPostStore) GetTagFeed(ctx context.Context, userID int64, slug string, fq PagintatedFeedQuery) ([]PostWithMetadata, string, error) {
+	scope := `p.tags @> ARRAY[$12]::varchar[] AND p.deleted IS NOT TRUE`
+	return s.pagedFeed(ctx, scope, userID, fq, slug)
+}
+
+// pagedFeed runs the feed and, for the sorts by creation time, returns the
+// cursor of the next page, empty on the last page.
+func (s *PostgresPostStore) pagedFeed(ctx context.Context, scope string, userID int64, fq PagintatedFeedQuery, args ...any) ([]PostWithMetadata, string, error) {
+	limit := fq.Limit
+	keyset := fq.Keyset()
+	if keyset {
+		fq.Limit++
+	}
+
+	feed, err := s.feed(ctx, scope, feedOrderBy(fq.Sort), userID, fq, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if keyset && len(feed) > limit {
+		feed = feed[:limit]
+		last := feed[len(feed)-1]
+		next = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return feed, next, nil
+}
+
+// GetExploreFeed lists the live posts of everyone. userID is the viewer, 0 for
+// anonymous requests, and only used to flag their bookmarks. Trending holds the
+// ids of the trending posts in order and is only used by the trending sort.
+func (s *PostgresPostStore) GetExploreFeed(ctx context.Context, userID int64, eq ExploreQuery, trending []int64) ([]PostWithMetadata, error) {
+	fq := PagintatedFeedQuery{
+		Limit:  eq.Limit,
+		Offset: eq.Offset,
+		Tags:   eq.Tags,
+	}
+
+	switch eq.Sort {
+	case "trending":
+		scope := `p.deleted IS NOT TRUE AND p.id = ANY($12::bigint[])`
+		return s.feed(ctx, scope, `array_position($12::bigint[], p.id)`, userID, fq, pq.Array(trending))
+	case "top":
+		scope := `p.deleted IS NOT TRUE AND p.created_at >= $12`
+		return s.feed(ctx, scope, `p.score + COUNT(c.id) DESC, p.created_at DESC`, userID, fq, eq.Since(time.Now()))
+	default:
+		return s.feed(ctx, `p.deleted IS NOT TRUE`, feedOrderBy("desc"), userID, fq)
+	}
+}
+
+// GetPublished lists the newest live posts for syndication, optionally only
+// those of an author or tagged with a tag. A zero authorID or an empty tag
+// disables the filter.
+func (s *PostgresPostStore) GetPublished(ctx context.Context, authorID int64, tag string, limit int) ([]PostWithMetadata, error) {
+	scope := `p.deleted IS NOT TRUE
+	    AND ($12::bigint = 0 OR p.user_id = $12)
+	    AND ($13::varchar = '' OR p.tags @> ARRAY[$13]::varchar[])`
+	fq := PagintatedFeedQuery{Limit: limit, Tags: []string{}}
+	return s.feed(ctx, scope, feedOrderBy("desc"), 0, fq, authorID, tag)
+}
+
+// GetTrendingScores ranks the live posts created after since by their votes and
+// comments, decayed by their age in hours raised to gravity.
+func (s *PostgresPostStore) GetTrendingScores(ctx context.Context, since time.Time, gravity float64, limit int) ([]PostScore, error) {
+	query := `
+	SELECT p.id,
+	    (p.score + COUNT(c.id))::float8 / POWER(EXTRACT(EPOCH FROM NOW() - p.created_at) / 3600 + 2, $2) AS trending
+	FROM posts p
+	LEFT JOIN comments c ON c.post_id = p.id
+	WHERE p.deleted IS NOT TRUE AND p.created_at >= $1
+	GROUP BY p.id
+	ORDER BY trending DESC, p.id DESC
+	LIMIT $3
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, since, gravity, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []PostScore
+	for rows.Next() {
+		var ps PostScore
+		if err := rows.Scan(&ps.PostID, &ps.Score); err != nil {
+			return nil, err
+		}
+		scores = append(scores, ps)
+	}
+	return scores, rows.Err()
 }
 
-func (s *PostgresPostStore) GetUserFeed(ctx context.Context, userID int64, fq PagintatedFeedQuery) ([]PostWithMetadata, error) {
+// feed runs the listing shared by the feeds. The scope restricts which posts are
+// listed, it can reference the extra arguments starting at $12. A cursor in the
+// query resumes a listing sorted by creation time after the cursor position.
+func (s *PostgresPostStore) feed(ctx context.Context, scope, orderBy string, userID int64, fq PagintatedFeedQuery, args ...any) ([]PostWithMetadata, error) {
+	var afterTime *time.Time
+	var afterID int64
+	if fq.Cursor != "" {
+		c, err := DecodeCursor(fq.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		afterTime, afterID = &c.CreatedAt, c.ID
+	}
+
+	keysetOp := "<"
+	if fq.Sort == "asc" {
+		keysetOp = ">"
+	}
+
 	query := `
 	SELECT
 	    p.id,
@@ -125,27 +504,42 @@ func (s *PostgresPostStore) GetUserFeed(ctx context.Context, userID int64, fq Pa
 	    p.title,
 	    p.content,
 	    p.created_at,
+	    p.updated_at,
 	    p.version,
 	    p.tags,
+	    p.score,
+	    p.accepted_comment_id,
+	    p.closed_reason,
+	    p.duplicate_of,
+	    p.closed_at,
 	    u.username,
-	    COUNT(c.id) AS comments_count
+	    COUNT(c.id) AS comments_count,
+	    (SELECT COUNT(*) FROM bookmarks bm WHERE bm.post_id = p.id) AS bookmarks_count,
+	    EXISTS (SELECT 1 FROM bookmarks bm WHERE bm.post_id = p.id AND bm.user_id = $1) AS bookmarked,
+	    b.amount,
+	    b.expires_at
 	FROM posts p
 	LEFT JOIN comments c ON c.post_id = p.id
+	LEFT JOIN bounties b ON b.post_id = p.id AND b.status = 'open'
 	JOIN users u ON p.user_id = u.id
 	WHERE
-	    (p.user_id = $1 OR p.user_id IN (
-	        SELECT user_id FROM followers WHERE follower_id = $1
-	    ))
+	    ` + scope + `
 	    AND ($4 = '' OR p.title ILIKE '%' || $4 || '%' OR p.content ILIKE '%' || $4 || '%')
 	    AND (p.tags @> $5 OR $5 = '{}')
-	GROUP BY p.id, u.username
-	ORDER BY p.created_at ` + fq.Sort + `
+	    AND ($6::boolean IS NULL OR (p.accepted_comment_id IS NOT NULL) = $6)
+	    AND (NOT $7 OR b.id IS NOT NULL)
+	    AND ($8::timestamptz IS NULL OR (p.created_at, p.id) ` + keysetOp + ` ($8, $9))
+	    AND ($10::timestamptz IS NULL OR p.created_at >= $10)
+	    AND ($11::timestamptz IS NULL OR p.created_at < $11)
+	GROUP BY p.id, u.username, b.id
+	ORDER BY ` + orderBy + `
 	LIMIT $2 OFFSET $3
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.QueryContext(ctx, query, userID, fq.Limit, fq.Offset, fq.Search, pq.Array(fq.Tags)) //
+	args = append([]any{userID, fq.Limit, fq.Offset, fq.Search, pq.Array(fq.Tags), fq.Answered, fq.Bountied, afterTime, afterID, fq.Since, fq.Until}, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, err
@@ -154,21 +548,48 @@ func (s *PostgresPostStore) GetUserFeed(ctx context.Context, userID int64, fq Pa
 	var feed []PostWithMetadata
 	for rows.Next() {
 		var post PostWithMetadata
+		var closure postClosure
 		err = rows.Scan(
 			&post.ID,
 			&post.UserID,
 			&post.Title,
 			&post.Content,
 			&post.CreatedAt,
+			&post.UpdatedAt,
 			&post.Version,
 			pq.Array(&post.Tags),
+			&post.Score,
+			&post.AcceptedCommentID,
+			&closure.reason,
+			&closure.duplicateOf,
+			&closure.closedAt,
 			&post.User.Username,
 			&post.CommentCount,
+			&post.BookmarkCount,
+			&post.Bookmarked,
+			&post.BountyAmount,
+			&post.BountyExpiresAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		post.Banner = closure.banner()
 		feed = append(feed, post)
 	}
 	return feed, nil
 }
+
+func feedOrderBy(sort string) string {
+	switch sort {
+	case "top":
+		return "p.score DESC, p.created_at DESC"
+	case "updated":
+		return "p.updated_at DESC, p.id DESC"
+	case "comments":
+		return "COUNT(c.id) DESC, p.created_at DESC, p.id DESC"
+	case "asc":
+		return "p.created_at ASC, p.id ASC"
+	default:
+		return "p.created_at DESC, p.id DESC"
+	}
+}
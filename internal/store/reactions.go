@@ -0,0 +1,192 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ReactionToggle struct {
+	Reaction string `json:"reaction"`
+	Reacted  bool   `json:"reacted"`
+	Count    int    `json:"count"`
+}
+
+type Reactor struct {
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	Reaction  string    `json:"reaction"`
+	CreatedAt time.Time `json:"created_at"`
+	// id of the reaction, it keys the cursor along with CreatedAt
+	id int64
+}
+
+type ReactionQuery struct {
+	Limit    int    `json:"limit" validate:"gte=1,lte=100"`
+	Cursor   string `json:"cursor"`
+	Reaction string `json:"reaction" validate:"max=32"`
+}
+
+func (rq ReactionQuery) Parse(r *http.Request) (ReactionQuery, error) {
+	q := r.URL.Query()
+	limit := q.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return rq, err
+		}
+		rq.Limit = l
+	}
+
+	rq.Cursor = q.Get("cursor")
+	rq.Reaction = q.Get("reaction")
+	return rq, nil
+}
+
+// reactionTarget describes a table that can be reacted to, its reactions table
+// and the column in the reactions table referencing it.
+type reactionTarget struct {
+	reactions string
+	idColumn  string
+}
+
+var (
+	postReactionTarget    = reactionTarget{reactions: "post_reactions", idColumn: "post_id"}
+	commentReactionTarget = reactionTarget{reactions: "comment_reactions", idColumn: "comment_id"}
+)
+
+// reactionCountsQuery aggregates the reactions of the row aliased alias into a
+// JSON object of reaction counts.
+func reactionCountsQuery(t reactionTarget, alias string) string {
+	return `COALESCE((
+	    SELECT json_object_agg(r.reaction, r.count)
+	    FROM (SELECT reaction, COUNT(*) AS count FROM ` + t.reactions + ` WHERE ` + t.idColumn + ` = ` + alias + `.id GROUP BY reaction) r
+	), '{}')`
+}
+
+// reactionCounts scans the JSON produced by reactionCountsQuery.
+type reactionCounts map[string]int
+
+func (rc *reactionCounts) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		*rc = reactionCounts{}
+		return nil
+	}
+	return json.Unmarshal(data, rc)
+}
+
+type PostgresReactionStore struct {
+	db *sql.DB
+}
+
+func (s *PostgresReactionStore) TogglePost(ctx context.Context, postID, userID int64, reaction string) (*ReactionToggle, error) {
+	return s.toggle(ctx, postReactionTarget, postID, userID, reaction)
+}
+
+func (s *PostgresReactionStore) ToggleComment(ctx context.Context, commentID, userID int64, reaction string) (*ReactionToggle, error) {
+	return s.toggle(ctx, commentReactionTarget, commentID, userID, reaction)
+}
+
+func (s *PostgresReactionStore) GetPostReactors(ctx context.Context, postID int64, rq ReactionQuery) ([]Reactor, string, error) {
+	return s.reactors(ctx, postReactionTarget, postID, rq)
+}
+
+func (s *PostgresReactionStore) GetCommentReactors(ctx context.Context, commentID int64, rq ReactionQuery) ([]Reactor, string, error) {
+	return s.reactors(ctx, commentReactionTarget, commentID, rq)
+}
+
+// toggle removes the user's reaction if present and adds it otherwise.
+func (s *PostgresReactionStore) toggle(ctx context.Context, t reactionTarget, targetID, userID int64, reaction string) (*ReactionToggle, error) {
+	toggle := &ReactionToggle{Reaction: reaction}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `DELETE FROM ` + t.reactions + ` WHERE ` + t.idColumn + ` = $1 AND user_id = $2 AND reaction = $3`
+		res, err := tx.ExecContext(ctx, query, targetID, userID, reaction)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			query = `INSERT INTO ` + t.reactions + ` (` + t.idColumn + `, user_id, reaction) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+			if _, err := tx.ExecContext(ctx, query, targetID, userID, reaction); err != nil {
+				return err
+			}
+			toggle.Reacted = true
+		}
+
+		query = `SELECT COUNT(*) FROM ` + t.reactions + ` WHERE ` + t.idColumn + ` = $1 AND reaction = $2`
+		return tx.QueryRowContext(ctx, query, targetID, reaction).Scan(&toggle.Count)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toggle, nil
+}
+
+// reactors lists who reacted newest first, it returns the cursor of the next
+// page or an empty string on the last page.
+func (s *PostgresReactionStore) reactors(ctx context.Context, t reactionTarget, targetID int64, rq ReactionQuery) ([]Reactor, string, error) {
+	var afterTime *time.Time
+	var afterID int64
+	if rq.Cursor != "" {
+		c, err := DecodeCursor(rq.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		afterTime, afterID = &c.CreatedAt, c.ID
+	}
+
+	query := `
+	SELECT r.id, r.user_id, u.username, r.reaction, r.created_at
+	FROM ` + t.reactions + ` r
+	JOIN users u ON u.id = r.user_id
+	WHERE r.` + t.idColumn + ` = $1
+	    AND ($2 = '' OR r.reaction = $2)
+	    AND ($3::timestamptz IS NULL OR (r.created_at, r.id) < ($3, $4))
+	ORDER BY r.created_at DESC, r.id DESC
+	LIMIT $5`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query, targetID, rq.Reaction, afterTime, afterID, rq.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	reactors := []Reactor{}
+	for rows.Next() {
+		var reactor Reactor
+		if err := rows.Scan(&reactor.id, &reactor.UserID, &reactor.Username, &reactor.Reaction, &reactor.CreatedAt); err != nil {
+			return nil, "", err
+		}
+		reactors = append(reactors, reactor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(reactors) > rq.Limit {
+		reactors = reactors[:rq.Limit]
+		last := reactors[len(reactors)-1]
+		next = Cursor{CreatedAt: last.CreatedAt, ID: last.id}.Encode()
+	}
+	return reactors, next, nil
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RelatedPost is a post similar to another one, Similarity ranges from 0 to 1.
+type RelatedPost struct {
+	ID           int64     `json:"id"`
+	Title        string    `json:"title"`
+	Tags         []string  `json:"tags"`
+	Score        int       `json:"score"`
+	CommentCount int       `json:"comment_count"`
+	Answered     bool      `json:"answered"`
+	CreatedAt    time.Time `json:"created_at"`
+	User         PostUser  `json:"user"`
+	Similarity   float64   `json:"similarity"`
+}
+
+// GetRelated lists the live posts most similar to the post. The similarity
+// weighs the overlap of the tags (Jaccard index) at 0.5, the trigram similarity
+// of the titles at 0.3 and the full-text rank of the candidate against any word
+// of the title at 0.2. Candidates share a tag, have a similar title or match a
+// word of the title.
+func (s *PostgresPostStore) GetRelated(ctx context.Context, postID int64, limit int) ([]RelatedPost, error) {
+	query := `
+	WITH src AS (
+	    SELECT id, title, tags,
+	        NULLIF(replace(plainto_tsquery(search_language(), title)::text, '&', '|'), '')::tsquery AS terms
+	    FROM posts
+	    WHERE id = $1
+	),
+	candidates AS (
+	    SELECT p.id,
+	        0.5 * COALESCE(
+	            cardinality(ARRAY(SELECT unnest(p.tags) INTERSECT SELECT unnest(src.tags)))::float8
+	            / NULLIF(cardinality(ARRAY(SELECT unnest(p.tags) UNION SELECT unnest(src.tags))), 0), 0)
+	        + 0.3 * similarity(p.title, src.title)
+	        + 0.2 * COALESCE(ts_rank(p.search_vector, src.terms, 32), 0) AS similarity
+	    FROM posts p
+	    CROSS JOIN src
+	    WHERE p.id <> src.id
+	        AND p.deleted IS NOT TRUE
+	        AND (p.tags && src.tags OR p.title % src.title OR p.search_vector @@ src.terms)
+	)
+	SELECT
+	    p.id,
+	    p.title,
+	    p.tags,
+	    p.score,
+	    (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.deleted IS NOT TRUE),
+	    p.accepted_comment_id IS NOT NULL,
+	    p.created_at,
+	    u.id,
+	    u.username,
+	    r.similarity
+	FROM candidates r
+	JOIN posts p ON p.id = r.id
+	JOIN users u ON u.id = p.user_id
+	ORDER BY r.similarity DESC, p.id DESC
+	LIMIT $2
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, postID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	related := []RelatedPost{}
+	for rows.Next() {
+		var rp RelatedPost
+		err := rows.Scan(
+			&rp.ID,
+			&rp.Title,
+			pq.Array(&rp.Tags),
+			&rp.Score,
+			&rp.CommentCount,
+			&rp.Answered,
+			&rp.CreatedAt,
+			&rp.User.ID,
+			&rp.User.Username,
+			&rp.Similarity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		related = append(related, rp)
+	}
+	return related, rows.Err()
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	ReputationUpvote         = "upvote"
+	ReputationDownvote       = "downvote"
+	ReputationAcceptedAnswer = "accepted_answer"
+	ReputationReversal       = "reversal"
+	ReputationBountyOffered  = "bounty_offered"
+	ReputationBountyAwarded  = "bounty_awarded"
+)
+
+// ReputationPoints is the amount of reputation each event awards to the author
+// of the content. Reversals take back the points of the event they undo and
+// bounty events carry the amount of the bounty.
+var ReputationPoints = map[string]int{
+	ReputationUpvote:         10,
+	ReputationDownvote:       -2,
+	ReputationAcceptedAnswer: 15,
+}
+
+type ReputationEvent struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	ActorID   int64     `json:"actor_id"`
+	Type      string    `json:"type"`
+	Points    int       `json:"points"`
+	PostID    *int64    `json:"post_id,omitempty"`
+	CommentID *int64    `json:"comment_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newReputationEvent(userID, actorID int64, eventType string) ReputationEvent {
+	return ReputationEvent{
+		UserID:  userID,
+		ActorID: actorID,
+		Type:    eventType,
+		Points:  ReputationPoints[eventType],
+	}
+}
+
+// reversal returns the event undoing e.
+func (e ReputationEvent) reversal() ReputationEvent {
+	e.Type = ReputationReversal
+	e.Points = -e.Points
+	return e
+}
+
+// recordReputation appends the events to the ledger and applies their points
+// to the materialized reputation of the users, it must run in the transaction
+// that caused the events.
+func recordReputation(ctx context.Context, tx *sql.Tx, events ...ReputationEvent) error {
+	for _, e := range events {
+		if e.Points == 0 {
+			continue
+		}
+
+		query := `INSERT INTO reputation_events (user_id, actor_id, type, points, post_id, comment_id)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+		if _, err := tx.ExecContext(ctx, query, e.UserID, e.ActorID, e.Type, e.Points, e.PostID, e.CommentID); err != nil {
+			return err
+		}
+
+		query = `UPDATE users SET reputation = reputation + $1 WHERE id = $2`
+		if _, err := tx.ExecContext(ctx, query, e.Points, e.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
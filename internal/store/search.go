@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type SearchResult struct {
+	ID           int64     `json:"id"`
+	Title        string    `json:"title"`
+	Tags         []string  `json:"tags"`
+	Score        int       `json:"score"`
+	CommentCount int       `json:"comment_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	User         PostUser  `json:"user"`
+	// Highlight is the HTML escaped title with the matched words wrapped in
+	// <mark> tags
+	Highlight string `json:"highlight"`
+	// Snippet holds the fragments of the content matching the query, marked up
+	// like Highlight
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+type SearchQuery struct {
+	Query  string     `json:"q" validate:"required,max=200"`
+	Tags   []string   `json:"tags" validate:"max=5"`
+	Author string     `json:"author" validate:"max=100"`
+	Since  *time.Time `json:"since"`
+	Until  *time.Time `json:"until"`
+	Limit  int        `json:"limit" validate:"gte=1,lte=50"`
+	Offset int        `json:"offset" validate:"gte=0"`
+}
+
+func (sq SearchQuery) Parse(r *http.Request) (SearchQuery, error) {
+	q := r.URL.Query()
+	sq.Query = strings.TrimSpace(q.Get("q"))
+	sq.Author = q.Get("author")
+
+	if tags := q.Get("tags"); tags != "" {
+		sq.Tags = strings.Split(tags, ",")
+	}
+
+	limit := q.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return sq, err
+		}
+		sq.Limit = l
+	}
+
+	offset := q.Get("offset")
+	if offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil {
+			return sq, err
+		}
+		sq.Offset = o
+	}
+
+	if since := q.Get("since"); since != "" {
+		t, err := parseTime(since)
+		if err != nil {
+			return sq, fmt.Errorf("invalid since: %w", err)
+		}
+		sq.Since = &t
+	}
+
+	if until := q.Get("until"); until != "" {
+		t, err := parseTime(until)
+		if err != nil {
+			return sq, fmt.Errorf("invalid until: %w", err)
+		}
+		sq.Until = &t
+	}
+
+	if sq.Since != nil && sq.Until != nil && !sq.Until.After(*sq.Since) {
+		return sq, errors.New("until must be after since")
+	}
+	return sq, nil
+}
+
+// ts_headline wraps the matches in control characters rather than tags, so
+// the text can be HTML escaped before the markers are turned into <mark> tags.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+// searchTitleOptions and searchHeadlineOptions configure ts_headline, the whole
+// title is kept and the snippet is made of up to two fragments.
+const (
+	searchTitleOptions    = `HighlightAll=true, StartSel="` + headlineStart + `", StopSel="` + headlineStop + `"`
+	searchHeadlineOptions = `StartSel="` + headlineStart + `", StopSel="` + headlineStop + `", MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=" … "`
+)
+
+var headlineReplacer = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// markHeadline HTML escapes a ts_headline output and marks its matches.
+func markHeadline(headline string) string {
+	return headlineReplacer.Replace(html.EscapeString(headline))
+}
+
+type PostgresSearchStore struct {
+	db *sql.DB
+}
+
+// Search ranks the live posts against the query with ts_rank over their search
+// vector, in which the title weighs more than the tags, the content and then
+// the comments. The query accepts the web search syntax: quoted phrases, OR
+// and -word.
+func (s *PostgresSearchStore) Search(ctx context.Context, sq SearchQuery) ([]SearchResult, error) {
+	query := `
+	WITH q AS (SELECT websearch_to_tsquery(search_language(), $1) AS query)
+	SELECT
+	    p.id,
+	    p.title,
+	    p.tags,
+	    p.score,
+	    (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.deleted IS NOT TRUE),
+	    p.created_at,
+	    u.id,
+	    u.username,
+	    ts_headline(search_language(), p.title, q.query, $9),
+	    ts_headline(search_language(), p.content, q.query, $8),
+	    ts_rank(p.search_vector, q.query) AS rank
+	FROM posts p
+	CROSS JOIN q
+	JOIN users u ON u.id = p.user_id
+	WHERE p.search_vector @@ q.query
+	    AND p.deleted IS NOT TRUE
+	    AND (p.tags @> $2 OR $2 = '{}')
+	    AND ($3 = '' OR u.username = $3)
+	    AND ($4::timestamptz IS NULL OR p.created_at >= $4)
+	    AND ($5::timestamptz IS NULL OR p.created_at < $5)
+	ORDER BY rank DESC, p.created_at DESC
+	LIMIT $6 OFFSET $7
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, sq.Query, pq.Array(sq.Tags), sq.Author, sq.Since, sq.Until, sq.Limit, sq.Offset, searchHeadlineOptions, searchTitleOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var sr SearchResult
+		err := rows.Scan(
+			&sr.ID,
+			&sr.Title,
+			pq.Array(&sr.Tags),
+			&sr.Score,
+			&sr.CommentCount,
+			&sr.CreatedAt,
+			&sr.User.ID,
+			&sr.User.Username,
+			&sr.Highlight,
+			&sr.Snippet,
+			&sr.Rank,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sr.Highlight = markHeadline(sr.Highlight)
+		sr.Snippet = markHeadline(sr.Snippet)
+		results = append(results, sr)
+	}
+	return results, rows.Err()
+}
+
+// SearchDocument is the searchable content of a live post, it feeds search
+// indexes kept outside of Postgres.
+type SearchDocument struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Tags      []string  `json:"tags"`
+	Content   string    `json:"content"`
+	Comments  []string  `json:"comments"`
+	Score     int       `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+	User      PostUser  `json:"user"`
+}
+
+// GetDocuments fetches the documents of the posts, deleted and missing posts
+// are left out.
+func (s *PostgresSearchStore) GetDocuments(ctx context.Context, postIDs []int64) ([]SearchDocument, error) {
+	return s.documents(ctx, `p.id = ANY($1) ORDER BY p.id`, pq.Array(postIDs))
+}
+
+// ListDocuments fetches the documents of up to limit live posts with an id
+// greater than afterID, in id order.
+func (s *PostgresSearchStore) ListDocuments(ctx context.Context, afterID int64, limit int) ([]SearchDocument, error) {
+	return s.documents(ctx, `p.id > $1 ORDER BY p.id LIMIT $2`, afterID, limit)
+}
+
+func (s *PostgresSearchStore) documents(ctx context.Context, where string, args ...any) ([]SearchDocument, error) {
+	query := `
+	SELECT p.id, p.title, p.tags, p.content, p.score, p.created_at, u.id, u.username,
+	    ARRAY(SELECT c.content FROM comments c WHERE c.post_id = p.id AND c.deleted IS NOT TRUE ORDER BY c.id)
+	FROM posts p
+	JOIN users u ON u.id = p.user_id
+	WHERE p.deleted IS NOT TRUE AND ` + where
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []SearchDocument{}
+	for rows.Next() {
+		var d SearchDocument
+		err := rows.Scan(&d.ID, &d.Title, pq.Array(&d.Tags), &d.Content, &d.Score, &d.CreatedAt, &d.User.ID, &d.User.Username, pq.Array(&d.Comments))
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
@@ -5,16 +5,19 @@ import (
 	"database/sql"
 	"errors"
 	"time"
+
+	"github.com/theluminousartemis/inkspire/internal/ranking"
 )
 
 var (
-	ErrNotFound          = errors.New("record not found")
-	ErrConflict          = errors.New("record conflict")
-	ErrFollowConflict    = errors.New("follow conflict")
-	QueryTimeoutDuration = 5 * time.Second
-	ErrDuplicateEmail    = errors.New("duplicate email")
-	ErrDuplicateUsername = errors.New("duplicate username")
-	DeletedContent       = "[deleted]"
+	ErrNotFound               = errors.New("record not found")
+	ErrConflict               = errors.New("record conflict")
+	ErrFollowConflict         = errors.New("follow conflict")
+	QueryTimeoutDuration      = 5 * time.Second
+	ErrDuplicateEmail         = errors.New("duplicate email")
+	ErrDuplicateUsername      = errors.New("duplicate username")
+	DeletedContent            = "[deleted]"
+	ErrInsufficientReputation = errors.New("insufficient reputation")
 )
 
 type Storage struct {
@@ -23,7 +26,19 @@ type Storage struct {
 		GetByID(context.Context, int64) (*Post, error)
 		Delete(context.Context, int64) error
 		Update(context.Context, *Post) error
-		GetUserFeed(context.Context, int64, PagintatedFeedQuery) ([]PostWithMetadata, error)
+		UpdateCommentSettings(ctx context.Context, postID int64, settings CommentSettings) error
+		SetAcceptedAnswer(ctx context.Context, postID int64, commentID *int64) ([]ReputationEvent, error)
+		Close(ctx context.Context, postID int64, closure PostClosure) error
+		Reopen(context.Context, int64) error
+		VoteToClose(ctx context.Context, postID int64, closure PostClosure, threshold int) (*CloseVoteResult, error)
+		GetUserFeed(context.Context, int64, PagintatedFeedQuery) ([]PostWithMetadata, string, error)
+		GetUserFeedPosts(ctx context.Context, userID int64, postIDs []int64) ([]PostWithMetadata, error)
+		GetRankedUserFeed(ctx context.Context, userID int64, fq PagintatedFeedQuery, weights ranking.Weights, candidates int) ([]PostWithMetadata, error)
+		GetTagFeed(ctx context.Context, userID int64, slug string, fq PagintatedFeedQuery) ([]PostWithMetadata, string, error)
+		GetExploreFeed(ctx context.Context, userID int64, eq ExploreQuery, trending []int64) ([]PostWithMetadata, error)
+		GetPublished(ctx context.Context, authorID int64, tag string, limit int) ([]PostWithMetadata, error)
+		GetTrendingScores(ctx context.Context, since time.Time, gravity float64, limit int) ([]PostScore, error)
+		GetRelated(ctx context.Context, postID int64, limit int) ([]RelatedPost, error)
 	}
 	Users interface {
 		Create(context.Context, *sql.Tx, *User) error
@@ -35,26 +50,107 @@ type Storage struct {
 	}
 	Comments interface {
 		Create(context.Context, *Comment) error
-		GetByPostID(context.Context, int64) ([]*Comment, error)
-		Delete(context.Context, int64) error
+		Delete(context.Context, int64) ([]ReputationEvent, error)
 		GetByID(context.Context, int64) (*Comment, error)
+		Update(ctx context.Context, comment *Comment, editorID int64) error
+		GetEdits(ctx context.Context, commentID int64) ([]CommentEdit, error)
+		GetPage(ctx context.Context, postID int64, acceptedID *int64, cq CommentQuery) ([]*Comment, string, error)
+		GetPath(ctx context.Context, commentID int64) ([]*Comment, error)
+		GetDepth(ctx context.Context, commentID int64) (int, error)
 	}
 	Followers interface {
 		Follow(ctx context.Context, followerID, userID int64) error
 		Unfollow(ctx context.Context, followerID, userID int64) error
+		IsFollowing(ctx context.Context, followerID, userID int64) (bool, error)
 	}
 	Roles interface {
 		GetByName(context.Context, string) (*Role, error)
 	}
+	Votes interface {
+		VotePost(ctx context.Context, postID, userID int64, value int) (*Vote, error)
+		VoteComment(ctx context.Context, commentID, userID int64, value int) (*Vote, error)
+	}
+	Badges interface {
+		Create(context.Context, *Badge) error
+		GetAll(context.Context) ([]Badge, error)
+		Delete(context.Context, int64) error
+		GetUnawarded(ctx context.Context, userID int64, metrics []string) ([]Badge, error)
+		GetByUserID(context.Context, int64) ([]UserBadge, error)
+		Award(ctx context.Context, userID, badgeID int64) (bool, error)
+		Metric(ctx context.Context, userID int64, metric string) (int, error)
+		RecordActivity(context.Context, int64) error
+	}
+	Bounties interface {
+		Create(context.Context, *Bounty) error
+		GetOpenByPostID(context.Context, int64) (*Bounty, error)
+		ProcessExpired(context.Context) ([]Bounty, error)
+	}
+	Bookmarks interface {
+		Add(context.Context, *Bookmark) error
+		Remove(ctx context.Context, userID, postID int64) error
+		Exists(ctx context.Context, userID, postID int64) (bool, error)
+		GetByUserID(context.Context, int64, BookmarkQuery) ([]Bookmark, string, error)
+		GetCollections(context.Context, int64) ([]BookmarkCollection, error)
+	}
+	Tags interface {
+		GetAll(context.Context, TagQuery) ([]Tag, error)
+		GetBySlug(context.Context, string) (*Tag, error)
+		Canonical(context.Context, []string) ([]string, error)
+		Update(context.Context, *Tag) error
+		AddSynonym(ctx context.Context, tagID int64, synonym string) error
+		RemoveSynonym(ctx context.Context, tagID int64, synonym string) error
+		Rename(ctx context.Context, tag *Tag, slug string) ([]int64, error)
+		Merge(ctx context.Context, sourceID, targetID int64) ([]int64, error)
+		Subscribe(ctx context.Context, userID, tagID int64, kind string) error
+		Unsubscribe(ctx context.Context, userID, tagID int64, kind string) error
+		GetSubscriptions(context.Context, int64) ([]TagSubscription, error)
+	}
+	Timelines interface {
+		GetAudience(ctx context.Context, postID int64) ([]int64, error)
+		GetRecent(ctx context.Context, userID int64, limit int) ([]int64, error)
+		GetAuthorPosts(ctx context.Context, userID, authorID int64, limit int) (inFeed, outOfFeed []int64, err error)
+	}
+	Search interface {
+		Search(context.Context, SearchQuery) ([]SearchResult, error)
+		GetDocuments(context.Context, []int64) ([]SearchDocument, error)
+		ListDocuments(ctx context.Context, afterID int64, limit int) ([]SearchDocument, error)
+	}
+	Reactions interface {
+		TogglePost(ctx context.Context, postID, userID int64, reaction string) (*ReactionToggle, error)
+		ToggleComment(ctx context.Context, commentID, userID int64, reaction string) (*ReactionToggle, error)
+		GetPostReactors(context.Context, int64, ReactionQuery) ([]Reactor, string, error)
+		GetCommentReactors(context.Context, int64, ReactionQuery) ([]Reactor, string, error)
+	}
+	Mentions interface {
+		Set(ctx context.Context, src MentionSource, usernames []string) ([]Mention, []Mention, error)
+		GetByPostID(context.Context, int64) ([]Mention, error)
+	}
+	Notifications interface {
+		Create(context.Context, *Notification) error
+		GetByUserID(context.Context, int64, NotificationQuery) ([]Notification, string, error)
+		CountUnread(context.Context, int64) (int, error)
+		MarkRead(ctx context.Context, userID, notificationID int64) error
+		MarkAllRead(context.Context, int64) error
+	}
 }
 
 func NewPostgresStorage(db *sql.DB) Storage {
 	return Storage{
-		Posts:     &PostgresPostStore{db},
-		Users:     &PostgresUserStore{db},
-		Comments:  &PostgresCommentStore{db},
-		Followers: &PostgresFollowerStore{db},
-		Roles:     &PostgresRoleStore{db},
+		Posts:         &PostgresPostStore{db},
+		Users:         &PostgresUserStore{db},
+		Comments:      &PostgresCommentStore{db},
+		Followers:     &PostgresFollowerStore{db},
+		Roles:         &PostgresRoleStore{db},
+		Votes:         &PostgresVoteStore{db},
+		Badges:        &PostgresBadgeStore{db},
+		Bounties:      &PostgresBountyStore{db},
+		Bookmarks:     &PostgresBookmarkStore{db},
+		Reactions:     &PostgresReactionStore{db},
+		Tags:          &PostgresTagStore{db},
+		Search:        &PostgresSearchStore{db},
+		Timelines:     &PostgresTimelineStore{db},
+		Mentions:      &PostgresMentionStore{db},
+		Notifications: &PostgresNotificationStore{db},
 	}
 }
 
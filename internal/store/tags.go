@@ -0,0 +1,499 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+type Tag struct {
+	ID          int64     `json:"id"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	UsageCount  int       `json:"usage_count"`
+	Synonyms    []string  `json:"synonyms"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+const (
+	TagFollow = "follow"
+	TagIgnore = "ignore"
+)
+
+// TagSubscription is a tag the user follows, pulling its posts into their feed,
+// or ignores, hiding its posts from the feed.
+type TagSubscription struct {
+	Tag       string    `json:"tag"`
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TagQuery struct {
+	Limit  int    `json:"limit" validate:"gte=1,lte=100"`
+	Offset int    `json:"offset" validate:"gte=0"`
+	Search string `json:"search" validate:"max=100"`
+}
+
+func (tq TagQuery) Parse(r *http.Request) (TagQuery, error) {
+	q := r.URL.Query()
+	limit := q.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return tq, err
+		}
+		tq.Limit = l
+	}
+
+	offset := q.Get("offset")
+	if offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil {
+			return tq, err
+		}
+		tq.Offset = o
+	}
+
+	tq.Search = NormalizeTag(q.Get("search"))
+	return tq, nil
+}
+
+// NormalizeTag turns a free-form tag into its slug: lower case, runs of spaces,
+// dashes and underscores collapsed into a single dash and anything outside of
+// a-z, 0-9, '+', '#' and '.' dropped. It mirrors the normalization done by the
+// tags migration.
+func NormalizeTag(tag string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(tag) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '+', r == '#', r == '.':
+			b.WriteRune(r)
+			dash = false
+		case r == '-', r == '_', unicode.IsSpace(r):
+			if b.Len() > 0 && !dash {
+				b.WriteByte('-')
+				dash = true
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// normalizeTags normalizes the tags, dropping empty and duplicate slugs while
+// keeping the original order.
+func normalizeTags(tags []string) []string {
+	slugs := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		slug := NormalizeTag(t)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+	}
+	return slugs
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// canonicalTags normalizes the tags and maps synonyms to their canonical tag.
+func canonicalTags(ctx context.Context, db querier, tags []string) ([]string, error) {
+	slugs := normalizeTags(tags)
+	if len(slugs) == 0 {
+		return slugs, nil
+	}
+
+	query := `SELECT s.slug, t.slug FROM tag_synonyms s JOIN tags t ON t.id = s.tag_id WHERE s.slug = ANY($1)`
+	rows, err := db.QueryContext(ctx, query, pq.Array(slugs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	canonical := make(map[string]string)
+	for rows.Next() {
+		var synonym, slug string
+		if err := rows.Scan(&synonym, &slug); err != nil {
+			return nil, err
+		}
+		canonical[synonym] = slug
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, slug := range slugs {
+		if c, ok := canonical[slug]; ok {
+			slugs[i] = c
+		}
+	}
+	return normalizeTags(slugs), nil
+}
+
+// resolveTags maps the tags to their canonical slugs and creates the tags seen
+// for the first time.
+func resolveTags(ctx context.Context, tx *sql.Tx, tags []string) ([]string, error) {
+	slugs, err := canonicalTags(ctx, tx, tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(slugs) == 0 {
+		return slugs, nil
+	}
+
+	query := `INSERT INTO tags (slug) SELECT unnest($1::varchar[]) ON CONFLICT (slug) DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, pq.Array(slugs)); err != nil {
+		return nil, err
+	}
+	return slugs, nil
+}
+
+// refreshTagCounts recounts the live posts using each of the tags.
+func refreshTagCounts(ctx context.Context, db execer, slugs []string) error {
+	if len(slugs) == 0 {
+		return nil
+	}
+	query := `UPDATE tags t SET usage_count = (
+		SELECT COUNT(*) FROM posts p WHERE p.tags @> ARRAY[t.slug] AND p.deleted IS NOT TRUE
+	) WHERE t.slug = ANY($1)`
+	_, err := db.ExecContext(ctx, query, pq.Array(slugs))
+	return err
+}
+
+// replaceTag swaps the tag $1 for $2 on every post using it, without
+// duplicating $2 on posts that already have both. It returns the ids of the
+// retagged posts.
+func replaceTag(ctx context.Context, tx *sql.Tx, from, to string) ([]int64, error) {
+	query := `UPDATE posts p SET tags = ARRAY(
+		SELECT t.tag FROM unnest(array_replace(p.tags, $1, $2)) WITH ORDINALITY AS t(tag, ord)
+		GROUP BY t.tag ORDER BY MIN(t.ord)
+	) WHERE p.tags @> ARRAY[$1]::varchar[]
+	RETURNING p.id`
+	rows, err := tx.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+type PostgresTagStore struct {
+	db *sql.DB
+}
+
+func (s *PostgresTagStore) GetAll(ctx context.Context, tq TagQuery) ([]Tag, error) {
+	query := `SELECT t.id, t.slug, t.description, t.usage_count, t.created_at,
+	ARRAY(SELECT s.slug FROM tag_synonyms s WHERE s.tag_id = t.id ORDER BY s.slug)
+	FROM tags t
+	WHERE ($3 = '' OR t.slug LIKE $3 || '%')
+	ORDER BY t.usage_count DESC, t.slug
+	LIMIT $1 OFFSET $2`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, tq.Limit, tq.Offset, tq.Search)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(&t.ID, &t.Slug, &t.Description, &t.UsageCount, &t.CreatedAt, pq.Array(&t.Synonyms)); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// GetBySlug fetches a tag by its slug or by one of its synonyms.
+func (s *PostgresTagStore) GetBySlug(ctx context.Context, slug string) (*Tag, error) {
+	query := `SELECT t.id, t.slug, t.description, t.usage_count, t.created_at,
+	ARRAY(SELECT s.slug FROM tag_synonyms s WHERE s.tag_id = t.id ORDER BY s.slug)
+	FROM tags t
+	WHERE t.slug = $1 OR t.id = (SELECT tag_id FROM tag_synonyms WHERE slug = $1)`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	t := &Tag{}
+	err := s.db.QueryRowContext(ctx, query, NormalizeTag(slug)).Scan(&t.ID, &t.Slug, &t.Description, &t.UsageCount, &t.CreatedAt, pq.Array(&t.Synonyms))
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Canonical maps the tags to their canonical slugs without creating missing
+// tags, it is used to filter listings by tag.
+func (s *PostgresTagStore) Canonical(ctx context.Context, tags []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	return canonicalTags(ctx, s.db, tags)
+}
+
+func (s *PostgresTagStore) Update(ctx context.Context, tag *Tag) error {
+	query := `UPDATE tags SET description = $1 WHERE id = $2`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, query, tag.Description, tag.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AddSynonym makes the slug an alias of the tag. Slugs already in use as a tag
+// are rejected with ErrConflict, those have to be merged instead.
+func (s *PostgresTagStore) AddSynonym(ctx context.Context, tagID int64, synonym string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var exists bool
+		query := `SELECT EXISTS (SELECT 1 FROM tags WHERE slug = $1)`
+		if err := tx.QueryRowContext(ctx, query, synonym).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return ErrConflict
+		}
+
+		query = `INSERT INTO tag_synonyms (slug, tag_id) VALUES ($1, $2)`
+		if _, err := tx.ExecContext(ctx, query, synonym, tagID); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return ErrConflict
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (s *PostgresTagStore) RemoveSynonym(ctx context.Context, tagID int64, synonym string) error {
+	query := `DELETE FROM tag_synonyms WHERE tag_id = $1 AND slug = $2`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, query, tagID, synonym)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Rename changes the slug of the tag on the tag and on every post using it. The
+// old slug is kept as a synonym so existing links and clients keep working. It
+// returns the ids of the retagged posts.
+func (s *PostgresTagStore) Rename(ctx context.Context, tag *Tag, slug string) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var retagged []int64
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var old string
+		query := `SELECT slug FROM tags WHERE id = $1 FOR UPDATE`
+		if err := tx.QueryRowContext(ctx, query, tag.ID).Scan(&old); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		//renaming to one of its own synonyms swaps the two
+		query = `DELETE FROM tag_synonyms WHERE slug = $1 AND tag_id = $2`
+		if _, err := tx.ExecContext(ctx, query, slug, tag.ID); err != nil {
+			return err
+		}
+
+		var taken bool
+		query = `SELECT EXISTS (SELECT 1 FROM tag_synonyms WHERE slug = $1)`
+		if err := tx.QueryRowContext(ctx, query, slug).Scan(&taken); err != nil {
+			return err
+		}
+		if taken {
+			return ErrConflict
+		}
+
+		query = `UPDATE tags SET slug = $1 WHERE id = $2`
+		if _, err := tx.ExecContext(ctx, query, slug, tag.ID); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return ErrConflict
+			}
+			return err
+		}
+
+		var err error
+		if retagged, err = replaceTag(ctx, tx, old, slug); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO tag_synonyms (slug, tag_id) VALUES ($1, $2)`
+		if _, err := tx.ExecContext(ctx, query, old, tag.ID); err != nil {
+			return err
+		}
+		tag.Slug = slug
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return retagged, nil
+}
+
+// Merge folds the source tag into the target: posts are retagged, the synonyms
+// of the source move over to the target and the source slug becomes one of
+// them. It returns the ids of the retagged posts.
+func (s *PostgresTagStore) Merge(ctx context.Context, sourceID, targetID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var retagged []int64
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var source, target, description string
+		query := `SELECT slug, description FROM tags WHERE id = $1 FOR UPDATE`
+		if err := tx.QueryRowContext(ctx, query, sourceID).Scan(&source, &description); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		query = `SELECT slug FROM tags WHERE id = $1 FOR UPDATE`
+		if err := tx.QueryRowContext(ctx, query, targetID).Scan(&target); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		var err error
+		if retagged, err = replaceTag(ctx, tx, source, target); err != nil {
+			return err
+		}
+
+		query = `UPDATE tag_synonyms SET tag_id = $1 WHERE tag_id = $2`
+		if _, err := tx.ExecContext(ctx, query, targetID, sourceID); err != nil {
+			return err
+		}
+
+		//users subscribed to both keep their subscription to the target
+		query = `UPDATE tag_subscriptions SET tag_id = $1 WHERE tag_id = $2
+		AND user_id NOT IN (SELECT user_id FROM tag_subscriptions WHERE tag_id = $1)`
+		if _, err := tx.ExecContext(ctx, query, targetID, sourceID); err != nil {
+			return err
+		}
+
+		query = `UPDATE tags SET description = $1 WHERE id = $2 AND description = ''`
+		if _, err := tx.ExecContext(ctx, query, description, targetID); err != nil {
+			return err
+		}
+
+		query = `DELETE FROM tags WHERE id = $1`
+		if _, err := tx.ExecContext(ctx, query, sourceID); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO tag_synonyms (slug, tag_id) VALUES ($1, $2)`
+		if _, err := tx.ExecContext(ctx, query, source, targetID); err != nil {
+			return err
+		}
+		return refreshTagCounts(ctx, tx, []string{target})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return retagged, nil
+}
+
+// Subscribe follows or ignores the tag, replacing the previous subscription of
+// the user to it.
+func (s *PostgresTagStore) Subscribe(ctx context.Context, userID, tagID int64, kind string) error {
+	query := `INSERT INTO tag_subscriptions (user_id, tag_id, kind) VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, tag_id) DO UPDATE SET kind = EXCLUDED.kind, created_at = NOW()`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	_, err := s.db.ExecContext(ctx, query, userID, tagID, kind)
+	return err
+}
+
+func (s *PostgresTagStore) Unsubscribe(ctx context.Context, userID, tagID int64, kind string) error {
+	query := `DELETE FROM tag_subscriptions WHERE user_id = $1 AND tag_id = $2 AND kind = $3`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, query, userID, tagID, kind)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresTagStore) GetSubscriptions(ctx context.Context, userID int64) ([]TagSubscription, error) {
+	query := `SELECT t.slug, ts.kind, ts.created_at FROM tag_subscriptions ts
+	JOIN tags t ON t.id = ts.tag_id
+	WHERE ts.user_id = $1
+	ORDER BY ts.kind, t.slug`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []TagSubscription{}
+	for rows.Next() {
+		var ts TagSubscription
+		if err := rows.Scan(&ts.Tag, &ts.Kind, &ts.CreatedAt); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, ts)
+	}
+	return subscriptions, rows.Err()
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// PostgresTimelineStore answers the questions needed to keep the timelines,
+// the precomputed user feeds, in sync with the posts and follows.
+type PostgresTimelineStore struct {
+	db *sql.DB
+}
+
+// GetAudience lists the users whose feed contains the post: its author, the
+// followers of the author and the followers of its tags, minus those ignoring
+// one of its tags.
+func (s *PostgresTimelineStore) GetAudience(ctx context.Context, postID int64) ([]int64, error) {
+	query := `
+	WITH post AS (SELECT user_id, COALESCE(tags, '{}') AS tags FROM posts WHERE id = $1)
+	SELECT post.user_id FROM post
+	UNION
+	SELECT f.follower_id FROM followers f JOIN post ON f.user_id = post.user_id
+	UNION
+	SELECT ts.user_id FROM tag_subscriptions ts
+	JOIN tags t ON t.id = ts.tag_id
+	JOIN post ON t.slug = ANY(post.tags)
+	WHERE ts.kind = 'follow'
+	EXCEPT
+	SELECT ts.user_id FROM tag_subscriptions ts
+	JOIN tags t ON t.id = ts.tag_id
+	JOIN post ON t.slug = ANY(post.tags)
+	WHERE ts.kind = 'ignore' AND ts.user_id <> post.user_id
+	`
+	return s.ids(ctx, query, postID)
+}
+
+// GetRecent lists the ids of the newest live posts of the user feed.
+func (s *PostgresTimelineStore) GetRecent(ctx context.Context, userID int64, limit int) ([]int64, error) {
+	query := `
+	SELECT p.id FROM posts p
+	WHERE ` + userFeedScope + ` AND p.deleted IS NOT TRUE
+	ORDER BY p.id DESC
+	LIMIT $2
+	`
+	return s.ids(ctx, query, userID, limit)
+}
+
+// GetAuthorPosts splits the newest live posts of the author by whether they
+// belong to the user feed, it is used after the user followed or unfollowed
+// the author.
+func (s *PostgresTimelineStore) GetAuthorPosts(ctx context.Context, userID, authorID int64, limit int) (inFeed, outOfFeed []int64, err error) {
+	query := `
+	SELECT p.id, ` + userFeedScope + ` FROM posts p
+	WHERE p.user_id = $2 AND p.deleted IS NOT TRUE
+	ORDER BY p.id DESC
+	LIMIT $3
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID, authorID, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var in bool
+		if err := rows.Scan(&id, &in); err != nil {
+			return nil, nil, err
+		}
+		if in {
+			inFeed = append(inFeed, id)
+		} else {
+			outOfFeed = append(outOfFeed, id)
+		}
+	}
+	return inFeed, outOfFeed, rows.Err()
+}
+
+func (s *PostgresTimelineStore) ids(ctx context.Context, query string, args ...any) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetUserFeedPosts hydrates the posts of a timeline, newest first. Posts that
+// were deleted or no longer belong to the user feed are left out.
+func (s *PostgresPostStore) GetUserFeedPosts(ctx context.Context, userID int64, postIDs []int64) ([]PostWithMetadata, error) {
+	scope := userFeedScope + ` AND p.deleted IS NOT TRUE AND p.id = ANY($12::bigint[])`
+	fq := PagintatedFeedQuery{Limit: len(postIDs), Tags: []string{}}
+	return s.feed(ctx, scope, `p.id DESC`, userID, fq, pq.Array(postIDs))
+}
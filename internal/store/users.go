@@ -11,14 +11,15 @@ import (
 )
 
 type User struct {
-	ID        int64     `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Password  password  `json:"-"`
-	CreatedAt time.Time `json:"created_at"`
-	IsActive  bool      `json:"is_active"`
-	RoleID    int64     `json:"role_id"`
-	Role      Role      `json:"role"`
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	Password   password  `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	IsActive   bool      `json:"is_active"`
+	Reputation int       `json:"reputation"`
+	RoleID     int64     `json:"role_id"`
+	Role       Role      `json:"role"`
 }
 
 type password struct {
@@ -81,14 +82,14 @@ RETURNING id, created_at`
 
 func (s *PostgresUserStore) GetByID(ctx context.Context, id int64) (*User, error) {
 	query := `
-SELECT users.id, username, email, password,created_at, roles.*
+SELECT users.id, username, email, password,created_at, reputation, roles.*
 FROM users
 JOIN roles ON (users.role_id = roles.id)
 WHERE users.id = $1 AND is_active = true`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 	var user User
-	err := s.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Username, &user.Email, &user.Password.hash, &user.CreatedAt, &user.Role.ID, &user.Role.Name, &user.Role.Level, &user.Role.Description)
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Username, &user.Email, &user.Password.hash, &user.CreatedAt, &user.Reputation, &user.Role.ID, &user.Role.Name, &user.Role.Level, &user.Role.Description)
 	if err != nil {
 		switch err {
 		case sql.ErrNoRows:
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type Vote struct {
+	UserID   int64 `json:"user_id"`
+	Value    int   `json:"value"`
+	Previous int   `json:"-"`
+	Score    int   `json:"score"`
+	// AuthorID is the author of the voted post or comment
+	AuthorID int64 `json:"-"`
+}
+
+// voteTarget describes a votable table, its votes table and the column in the
+// votes table referencing it.
+type voteTarget struct {
+	table    string
+	votes    string
+	idColumn string
+}
+
+// reputationEvent builds the ledger event for a vote of value by voterID on the
+// target authored by authorID.
+func (t voteTarget) reputationEvent(targetID, authorID, voterID int64, value int) ReputationEvent {
+	eventType := ReputationUpvote
+	if value < 0 {
+		eventType = ReputationDownvote
+	}
+	e := newReputationEvent(authorID, voterID, eventType)
+	if t.table == postVoteTarget.table {
+		e.PostID = &targetID
+	} else {
+		e.CommentID = &targetID
+	}
+	return e
+}
+
+var (
+	postVoteTarget    = voteTarget{table: "posts", votes: "post_votes", idColumn: "post_id"}
+	commentVoteTarget = voteTarget{table: "comments", votes: "comment_votes", idColumn: "comment_id"}
+)
+
+type PostgresVoteStore struct {
+	db *sql.DB
+}
+
+func (s *PostgresVoteStore) VotePost(ctx context.Context, postID, userID int64, value int) (*Vote, error) {
+	return s.vote(ctx, postVoteTarget, postID, userID, value)
+}
+
+func (s *PostgresVoteStore) VoteComment(ctx context.Context, commentID, userID int64, value int) (*Vote, error) {
+	return s.vote(ctx, commentVoteTarget, commentID, userID, value)
+}
+
+// vote records the user's vote on the target (a value of 0 removes it) and
+// applies the difference to the target's score and its author's reputation in
+// the same transaction.
+func (s *PostgresVoteStore) vote(ctx context.Context, t voteTarget, targetID, userID int64, value int) (*Vote, error) {
+	vote := &Vote{UserID: userID, Value: value}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		//lock the target so concurrent votes on it are applied one at a time
+		query := `SELECT score, user_id FROM ` + t.table + ` WHERE id = $1 FOR UPDATE`
+		if err := tx.QueryRowContext(ctx, query, targetID).Scan(&vote.Score, &vote.AuthorID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		query = `SELECT value FROM ` + t.votes + ` WHERE ` + t.idColumn + ` = $1 AND user_id = $2`
+		err := tx.QueryRowContext(ctx, query, targetID, userID).Scan(&vote.Previous)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		if value == vote.Previous {
+			return nil
+		}
+
+		if value == 0 {
+			query = `DELETE FROM ` + t.votes + ` WHERE ` + t.idColumn + ` = $1 AND user_id = $2`
+			_, err = tx.ExecContext(ctx, query, targetID, userID)
+		} else {
+			query = `INSERT INTO ` + t.votes + ` (` + t.idColumn + `, user_id, value) VALUES ($1, $2, $3)
+			ON CONFLICT (` + t.idColumn + `, user_id) DO UPDATE SET value = EXCLUDED.value, updated_at = NOW()`
+			_, err = tx.ExecContext(ctx, query, targetID, userID, value)
+		}
+		if err != nil {
+			return err
+		}
+
+		query = `UPDATE ` + t.table + ` SET score = score + $1 WHERE id = $2 RETURNING score`
+		if err := tx.QueryRowContext(ctx, query, value-vote.Previous, targetID).Scan(&vote.Score); err != nil {
+			return err
+		}
+
+		//users do not earn reputation from their own content
+		if vote.AuthorID == userID {
+			return nil
+		}
+
+		var events []ReputationEvent
+		if vote.Previous != 0 {
+			events = append(events, t.reputationEvent(targetID, vote.AuthorID, userID, vote.Previous).reversal())
+		}
+		if value != 0 {
+			events = append(events, t.reputationEvent(targetID, vote.AuthorID, userID, value))
+		}
+		return recordReputation(ctx, tx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vote, nil
+}
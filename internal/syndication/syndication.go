@@ -0,0 +1,204 @@
+package syndication
+
+import (
+	"encoding/xml"
+	"html"
+	"strings"
+	"time"
+)
+
+// Feed is a list of entries rendered as Atom 1.0 or RSS 2.0. Link points to the
+// HTML page of the feed and Self to the feed document itself.
+type Feed struct {
+	Title       string
+	Description string
+	Link        string
+	Self        string
+	Items       []Item
+}
+
+// Item is an entry of a feed, its Link doubles as its unique id and Content is
+// HTML.
+type Item struct {
+	Title      string
+	Link       string
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+	Content    string
+}
+
+// Updated is the time the newest item was updated, the zero time for an empty
+// feed.
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+	for _, item := range f.Items {
+		if item.Updated.After(updated) {
+			updated = item.Updated
+		}
+	}
+	return updated
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomAuthor     `xml:"author"`
+	Link       atomLink       `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// Atom renders the feed as an Atom 1.0 document.
+func (f Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		ID:       f.Self,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated().UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.Self},
+			{Rel: "alternate", Type: "text/html", Href: f.Link},
+		},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        item.Link,
+			Title:     item.Title,
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Author:    atomAuthor{Name: item.Author},
+			Link:      atomLink{Rel: "alternate", Type: "text/html", Href: item.Link},
+			Content:   atomContent{Type: "html", Body: item.Content},
+		}
+		for _, c := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshal(doc)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS renders the feed as an RSS 2.0 document. The channel links to itself
+// with atom:link and items name their author with dc:creator since the RSS
+// author element requires an email address.
+func (f Feed) RSS() ([]byte, error) {
+	doc := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			Self:        atomLink{Rel: "self", Type: "application/rss+xml", Href: f.Self},
+		},
+	}
+	if updated := f.Updated(); !updated.IsZero() {
+		doc.Channel.LastBuildDate = updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, item := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.Link},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Creator:     item.Author,
+			Categories:  item.Categories,
+			Description: item.Content,
+		})
+	}
+	return marshal(doc)
+}
+
+func marshal(doc any) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// RenderText turns the plain text of a post into HTML: the text is escaped,
+// blank lines separate paragraphs and single line breaks are kept.
+func RenderText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var b strings.Builder
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		lines := strings.Split(paragraph, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		b.WriteString("<p>" + strings.Join(lines, "<br>") + "</p>")
+	}
+	return b.String()
+}
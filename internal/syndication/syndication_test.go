@@ -0,0 +1,177 @@
+package syndication
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	published = time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	updated   = time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+)
+
+func testFeed() Feed {
+	return Feed{
+		Title:       "Posts tagged <go> & more",
+		Description: "The newest posts",
+		Link:        "http://example.com/tags/go",
+		Self:        "https://api.example.com/v1/feeds/tags/go?format=atom&limit=10",
+		Items: []Item{
+			{
+				Title:      "Generics & <interfaces>",
+				Link:       "http://example.com/posts/1",
+				Author:     "alice",
+				Categories: []string{"go", "c++"},
+				Published:  published,
+				Updated:    updated,
+				Content:    RenderText("<script>alert(1)</script>"),
+			},
+			{
+				Title:     "Older post",
+				Link:      "http://example.com/posts/2",
+				Author:    "bob",
+				Published: published.Add(-time.Hour),
+				Updated:   published.Add(-time.Hour),
+			},
+		},
+	}
+}
+
+func TestFeedUpdated(t *testing.T) {
+	if got := testFeed().Updated(); !got.Equal(updated) {
+		t.Errorf("got %v, want the newest item update %v", got, updated)
+	}
+	if got := (Feed{}).Updated(); !got.IsZero() {
+		t.Errorf("got %v for an empty feed, want the zero time", got)
+	}
+}
+
+func TestAtom(t *testing.T) {
+	tests := []struct {
+		name string
+		feed Feed
+		want []string
+	}{
+		{
+			name: "escapes text and links",
+			feed: testFeed(),
+			want: []string{
+				`<title>Posts tagged &lt;go&gt; &amp; more</title>`,
+				`<link rel="self" type="application/atom+xml" href="https://api.example.com/v1/feeds/tags/go?format=atom&amp;limit=10"></link>`,
+				`<title>Generics &amp; &lt;interfaces&gt;</title>`,
+				`<category term="c++"></category>`,
+				`<content type="html">&lt;p&gt;&amp;lt;script&amp;gt;alert(1)&amp;lt;/script&amp;gt;&lt;/p&gt;</content>`,
+			},
+		},
+		{
+			name: "formats dates as RFC 3339 in UTC",
+			feed: testFeed(),
+			want: []string{
+				`<updated>2026-03-02T18:00:00Z</updated>`,
+				`<published>2026-03-01T08:30:00Z</published>`,
+			},
+		},
+		{
+			name: "empty feed",
+			feed: Feed{Title: "Empty", Self: "http://api.example.com/v1/feeds"},
+			want: []string{
+				`<id>http://api.example.com/v1/feeds</id>`,
+				`<updated>0001-01-01T00:00:00Z</updated>`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := tt.feed.Atom()
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc := string(body)
+			if !strings.HasPrefix(doc, `<?xml version="1.0" encoding="UTF-8"?>`) {
+				t.Errorf("missing XML header in %s", doc)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(doc, want) {
+					t.Errorf("missing %s in\n%s", want, doc)
+				}
+			}
+		})
+	}
+}
+
+func TestRSS(t *testing.T) {
+	tests := []struct {
+		name    string
+		feed    Feed
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "escapes text and links",
+			feed: testFeed(),
+			want: []string{
+				`<title>Posts tagged &lt;go&gt; &amp; more</title>`,
+				`<atom:link rel="self" type="application/rss+xml" href="https://api.example.com/v1/feeds/tags/go?format=atom&amp;limit=10"></atom:link>`,
+				`<guid isPermaLink="true">http://example.com/posts/1</guid>`,
+				`<dc:creator>alice</dc:creator>`,
+				`<description>&lt;p&gt;&amp;lt;script&amp;gt;alert(1)&amp;lt;/script&amp;gt;&lt;/p&gt;</description>`,
+			},
+		},
+		{
+			name: "formats dates as RFC 1123 in UTC",
+			feed: testFeed(),
+			want: []string{
+				`<lastBuildDate>Mon, 02 Mar 2026 18:00:00 +0000</lastBuildDate>`,
+				`<pubDate>Sun, 01 Mar 2026 08:30:00 +0000</pubDate>`,
+			},
+		},
+		{
+			name:    "empty feed has no build date",
+			feed:    Feed{Title: "Empty"},
+			want:    []string{`<title>Empty</title>`},
+			notWant: []string{`lastBuildDate`, `<item>`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := tt.feed.RSS()
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc := string(body)
+			for _, want := range tt.want {
+				if !strings.Contains(doc, want) {
+					t.Errorf("missing %s in\n%s", want, doc)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(doc, notWant) {
+					t.Errorf("unexpected %s in\n%s", notWant, doc)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"escapes HTML", `<b>"bold"</b> & co`, `<p>&lt;b&gt;&#34;bold&#34;&lt;/b&gt; &amp; co</p>`},
+		{"paragraphs", "first\n\nsecond", "<p>first</p><p>second</p>"},
+		{"line breaks", "one\ntwo", "<p>one<br>two</p>"},
+		{"windows line endings", "one\r\ntwo\r\n\r\nthree", "<p>one<br>two</p><p>three</p>"},
+		{"extra blank lines", "\n\n\nfirst\n\n\n\nsecond\n\n", "<p>first</p><p>second</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderText(tt.text); got != tt.want {
+				t.Errorf("RenderText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package timeline
+
+import (
+	"context"
+
+	"github.com/theluminousartemis/inkspire/internal/store"
+	"github.com/theluminousartemis/inkspire/internal/store/cache"
+)
+
+// Service keeps a timeline per user in Redis, the ids of the newest posts of
+// their feed. New posts are pushed to the timelines of their audience when
+// they are created so reading the feed only hydrates a page of ids.
+type Service struct {
+	store store.Storage
+	cache cache.Storage
+}
+
+func New(store store.Storage, cache cache.Storage) *Service {
+	return &Service{store: store, cache: cache}
+}
+
+// Supports reports whether the feed query can be served from the timelines,
+// that is the newest posts first without any filter.
+func Supports(fq store.PagintatedFeedQuery) bool {
+	return fq.Sort == "desc" &&
+		fq.Search == "" &&
+		len(fq.Tags) == 0 &&
+		fq.Answered == nil &&
+		!fq.Bountied &&
+		fq.Since == nil &&
+		fq.Until == nil
+}
+
+// Feed reads a page of the user feed from their timeline, building it when
+// missing. ok is false when the page reaches past the posts kept in the
+// timeline and has to be read from the database instead.
+func (s *Service) Feed(ctx context.Context, userID int64, fq store.PagintatedFeedQuery) (feed []store.PostWithMetadata, next string, ok bool, err error) {
+	var beforeID int64
+	if fq.Cursor != "" {
+		c, err := store.DecodeCursor(fq.Cursor)
+		if err != nil {
+			return nil, "", false, err
+		}
+		beforeID = c.ID
+	}
+
+	ids, complete, found, err := s.cache.Timelines.Get(ctx, userID, beforeID, fq.Offset, fq.Limit+1)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if !found {
+		if err := s.Rebuild(ctx, userID); err != nil {
+			return nil, "", false, err
+		}
+		ids, complete, _, err = s.cache.Timelines.Get(ctx, userID, beforeID, fq.Offset, fq.Limit+1)
+		if err != nil {
+			return nil, "", false, err
+		}
+	}
+
+	more := len(ids) > fq.Limit
+	if !more && !complete {
+		return nil, "", false, nil
+	}
+	if more {
+		ids = ids[:fq.Limit]
+	}
+	if len(ids) == 0 {
+		return []store.PostWithMetadata{}, "", true, nil
+	}
+
+	feed, err = s.store.Posts.GetUserFeedPosts(ctx, userID, ids)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if more {
+		// the hydration drops the posts that left the feed, a page left empty
+		// gives no position for the cursor
+		if len(feed) == 0 {
+			return nil, "", false, nil
+		}
+		last := feed[len(feed)-1]
+		next = store.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return feed, next, true, nil
+}
+
+// Rebuild replaces the timeline of the user with the newest posts of their
+// feed.
+func (s *Service) Rebuild(ctx context.Context, userID int64) error {
+	ids, err := s.store.Timelines.GetRecent(ctx, userID, cache.TimelineSize)
+	if err != nil {
+		return err
+	}
+	return s.cache.Timelines.Set(ctx, userID, ids)
+}
+
+// Publish pushes the post to the timelines of its audience, it is also used
+// after an edit of the tags since they change who follows the post.
+func (s *Service) Publish(ctx context.Context, postID int64) error {
+	audience, err := s.store.Timelines.GetAudience(ctx, postID)
+	if err != nil {
+		return err
+	}
+	return s.cache.Timelines.Push(ctx, audience, postID)
+}
+
+// Retract removes the deleted post from the timelines of its audience.
+func (s *Service) Retract(ctx context.Context, postID int64) error {
+	audience, err := s.store.Timelines.GetAudience(ctx, postID)
+	if err != nil {
+		return err
+	}
+	return s.cache.Timelines.Remove(ctx, audience, postID)
+}
+
+// Follow backfills the timeline of the follower with the posts of the author.
+func (s *Service) Follow(ctx context.Context, followerID, authorID int64) error {
+	inFeed, _, err := s.store.Timelines.GetAuthorPosts(ctx, followerID, authorID, cache.TimelineSize)
+	if err != nil {
+		return err
+	}
+	return s.cache.Timelines.Push(ctx, []int64{followerID}, inFeed...)
+}
+
+// Unfollow removes the posts of the author from the timeline of the former
+// follower, keeping those still in their feed through a followed tag.
+func (s *Service) Unfollow(ctx context.Context, followerID, authorID int64) error {
+	_, outOfFeed, err := s.store.Timelines.GetAuthorPosts(ctx, followerID, authorID, cache.TimelineSize)
+	if err != nil {
+		return err
+	}
+	return s.cache.Timelines.Remove(ctx, []int64{followerID}, outOfFeed...)
+}
+
+// Reset drops the timeline of the user after a change that reshapes their
+// whole feed, such as following or ignoring a tag.
+func (s *Service) Reset(ctx context.Context, userID int64) error {
+	return s.cache.Timelines.Delete(ctx, userID)
+}
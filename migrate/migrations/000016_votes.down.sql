@@ -0,0 +1,5 @@
+DROP index IF EXISTS idx_posts_score;
+DROP TABLE IF EXISTS comment_votes;
+DROP TABLE IF EXISTS post_votes;
+ALTER TABLE comments DROP COLUMN score;
+ALTER TABLE posts DROP COLUMN score;
@@ -0,0 +1,26 @@
+ALTER TABLE posts ADD COLUMN score INT NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN score INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS post_votes (
+    post_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    value smallint NOT NULL CHECK (value IN (-1, 1)),
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS comment_votes (
+    comment_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    value smallint NOT NULL CHECK (value IN (-1, 1)),
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id),
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE index IF NOT EXISTS idx_posts_score ON posts(score);
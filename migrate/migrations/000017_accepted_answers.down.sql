@@ -0,0 +1,2 @@
+DROP index IF EXISTS idx_posts_accepted_comment_id;
+ALTER TABLE posts DROP COLUMN accepted_comment_id;
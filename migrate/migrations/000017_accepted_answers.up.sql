@@ -0,0 +1,6 @@
+ALTER TABLE posts
+ADD COLUMN accepted_comment_id bigint DEFAULT NULL,
+ADD CONSTRAINT fk_post_accepted_comment
+FOREIGN KEY (accepted_comment_id) REFERENCES comments(id) ON DELETE SET NULL;
+
+CREATE index IF NOT EXISTS idx_posts_accepted_comment_id ON posts(accepted_comment_id);
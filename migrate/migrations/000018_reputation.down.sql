@@ -0,0 +1,3 @@
+DROP index IF EXISTS idx_reputation_events_user_id;
+DROP TABLE IF EXISTS reputation_events;
+ALTER TABLE users DROP COLUMN reputation;
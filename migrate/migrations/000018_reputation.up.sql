@@ -0,0 +1,18 @@
+ALTER TABLE users ADD COLUMN reputation INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS reputation_events (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    actor_id bigint NOT NULL,
+    type varchar(50) NOT NULL,
+    points INT NOT NULL,
+    post_id bigint DEFAULT NULL,
+    comment_id bigint DEFAULT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE SET NULL,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE SET NULL
+);
+
+CREATE index IF NOT EXISTS idx_reputation_events_user_id ON reputation_events(user_id);
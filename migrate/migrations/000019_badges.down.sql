@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_activity;
+DROP TABLE IF EXISTS user_badges;
+DROP TABLE IF EXISTS badges;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS badges (
+    id bigserial PRIMARY KEY,
+    name varchar(100) NOT NULL UNIQUE,
+    description text NOT NULL DEFAULT '',
+    metric varchar(50) NOT NULL,
+    threshold INT NOT NULL CHECK (threshold > 0),
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_badges (
+    user_id bigint NOT NULL,
+    badge_id bigint NOT NULL,
+    awarded_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, badge_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (badge_id) REFERENCES badges(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_activity (
+    user_id bigint NOT NULL,
+    day date NOT NULL,
+    PRIMARY KEY (user_id, day),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO badges (name, description, metric, threshold) VALUES
+('First Post', 'Created a first post', 'posts', 1),
+('Helpful', 'Had 10 answers accepted', 'accepted_answers', 10),
+('Popular', 'Followed by 100 users', 'followers', 100),
+('Fanatic', 'Active 365 days in a row', 'streak_days', 365);
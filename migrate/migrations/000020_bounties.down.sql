@@ -0,0 +1,3 @@
+DROP index IF EXISTS idx_bounties_open_expires_at;
+DROP index IF EXISTS idx_bounties_open_post_id;
+DROP TABLE IF EXISTS bounties;
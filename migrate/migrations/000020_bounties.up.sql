@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS bounties (
+    id bigserial PRIMARY KEY,
+    post_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    amount INT NOT NULL CHECK (amount > 0),
+    status varchar(20) NOT NULL DEFAULT 'open',
+    awarded_comment_id bigint DEFAULT NULL,
+    expires_at timestamp(0) with time zone NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (awarded_comment_id) REFERENCES comments(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE index IF NOT EXISTS idx_bounties_open_post_id ON bounties(post_id) WHERE status = 'open';
+CREATE index IF NOT EXISTS idx_bounties_open_expires_at ON bounties(expires_at) WHERE status = 'open';
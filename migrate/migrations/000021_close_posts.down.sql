@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS post_close_votes;
+ALTER TABLE posts
+DROP COLUMN closed_reason,
+DROP COLUMN duplicate_of,
+DROP COLUMN closed_by,
+DROP COLUMN closed_at;
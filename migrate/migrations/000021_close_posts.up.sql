@@ -0,0 +1,21 @@
+ALTER TABLE posts
+ADD COLUMN closed_reason varchar(50) DEFAULT NULL,
+ADD COLUMN duplicate_of bigint DEFAULT NULL,
+ADD COLUMN closed_by bigint DEFAULT NULL,
+ADD COLUMN closed_at timestamp(0) with time zone DEFAULT NULL,
+ADD CONSTRAINT fk_post_duplicate_of
+FOREIGN KEY (duplicate_of) REFERENCES posts(id) ON DELETE SET NULL,
+ADD CONSTRAINT fk_post_closed_by
+FOREIGN KEY (closed_by) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS post_close_votes (
+    post_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    reason varchar(50) NOT NULL,
+    duplicate_of bigint DEFAULT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (duplicate_of) REFERENCES posts(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+DROP index IF EXISTS idx_bookmarks_post_id;
+DROP index IF EXISTS idx_bookmarks_user_id_created_at;
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS bookmark_collections;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS bookmark_collections (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    name varchar(100) NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    post_id bigint NOT NULL,
+    collection_id bigint DEFAULT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, post_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (collection_id) REFERENCES bookmark_collections(id) ON DELETE SET NULL
+);
+
+CREATE index IF NOT EXISTS idx_bookmarks_user_id_created_at ON bookmarks(user_id, created_at DESC, id DESC);
+CREATE index IF NOT EXISTS idx_bookmarks_post_id ON bookmarks(post_id);
@@ -0,0 +1,26 @@
+-- a user can hold several reactions on a target, the id breaks ties between
+-- reactions created at the same time when paging through reactors
+CREATE TABLE IF NOT EXISTS post_reactions (
+    id bigserial,
+    post_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    reaction varchar(32) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id, reaction),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS comment_reactions (
+    id bigserial,
+    comment_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    reaction varchar(32) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id, reaction),
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_reactions_reactors ON post_reactions(post_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_comment_reactions_reactors ON comment_reactions(comment_id, created_at DESC, id DESC);
@@ -0,0 +1,4 @@
+DROP index IF EXISTS idx_tags_usage_count;
+DROP index IF EXISTS idx_tag_synonyms_tag_id;
+DROP TABLE IF EXISTS tag_synonyms;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id bigserial PRIMARY KEY,
+    slug varchar(100) NOT NULL UNIQUE,
+    description text NOT NULL DEFAULT '',
+    usage_count int NOT NULL DEFAULT 0,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS tag_synonyms (
+    slug varchar(100) PRIMARY KEY,
+    tag_id bigint NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE index IF NOT EXISTS idx_tag_synonyms_tag_id ON tag_synonyms(tag_id);
+CREATE index IF NOT EXISTS idx_tags_usage_count ON tags(usage_count DESC, slug);
+
+-- normalize the free-form tags already on posts into slugs, keeping their order
+UPDATE posts p SET tags = COALESCE((
+    SELECT array_agg(n ORDER BY ord) FROM (
+        SELECT n, MIN(ord) AS ord FROM (
+            SELECT btrim(regexp_replace(regexp_replace(lower(t.tag), '[^a-z0-9+#.\s_-]', '', 'g'), '[\s_-]+', '-', 'g'), '-') AS n, t.ord
+            FROM unnest(p.tags) WITH ORDINALITY AS t(tag, ord)
+        ) s
+        WHERE n <> ''
+        GROUP BY n
+    ) d
+), '{}')
+WHERE p.tags IS NOT NULL;
+
+INSERT INTO tags (slug, usage_count)
+SELECT t.tag, COUNT(*) FILTER (WHERE p.deleted IS NOT TRUE)
+FROM posts p, unnest(p.tags) AS t(tag)
+GROUP BY t.tag
+ON CONFLICT (slug) DO NOTHING;
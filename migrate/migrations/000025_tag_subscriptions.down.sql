@@ -0,0 +1,2 @@
+DROP index IF EXISTS idx_tag_subscriptions_tag_id;
+DROP TABLE IF EXISTS tag_subscriptions;
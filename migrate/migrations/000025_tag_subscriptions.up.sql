@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS tag_subscriptions (
+    user_id bigint NOT NULL,
+    tag_id bigint NOT NULL,
+    kind varchar(10) NOT NULL CHECK (kind IN ('follow', 'ignore')),
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, tag_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE index IF NOT EXISTS idx_tag_subscriptions_tag_id ON tag_subscriptions(tag_id);
@@ -0,0 +1,8 @@
+DROP index IF EXISTS idx_posts_search_vector;
+DROP TRIGGER IF EXISTS comments_search_vector_update ON comments;
+DROP TRIGGER IF EXISTS posts_search_vector_update ON posts;
+DROP FUNCTION IF EXISTS comments_search_vector_trigger();
+DROP FUNCTION IF EXISTS posts_search_vector_trigger();
+ALTER TABLE posts DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS post_search_vector(bigint, text, varchar[], text);
+DROP FUNCTION IF EXISTS search_language();
@@ -0,0 +1,54 @@
+-- the text search configuration defaults to english and can be changed with
+-- ALTER DATABASE <db> SET inkspire.search_language = '<config>', followed by
+-- UPDATE posts SET search_vector = NULL to rebuild the vectors
+CREATE OR REPLACE FUNCTION search_language() RETURNS regconfig
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE(NULLIF(current_setting('inkspire.search_language', true), ''), 'english')::regconfig
+$$;
+
+CREATE OR REPLACE FUNCTION post_search_vector(post_id bigint, title text, tags varchar[], content text) RETURNS tsvector
+LANGUAGE sql STABLE AS $$
+    SELECT setweight(to_tsvector(search_language(), COALESCE(title, '')), 'A')
+        || setweight(to_tsvector(search_language(), COALESCE(array_to_string(tags, ' '), '')), 'B')
+        || setweight(to_tsvector(search_language(), COALESCE(content, '')), 'C')
+        || setweight(to_tsvector(search_language(), COALESCE((
+            SELECT string_agg(c.content, ' ') FROM comments c WHERE c.post_id = $1 AND c.deleted IS NOT TRUE
+        ), '')), 'D')
+$$;
+
+ALTER TABLE posts ADD COLUMN search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION posts_search_vector_trigger() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.search_vector := post_search_vector(NEW.id, NEW.title, NEW.tags, NEW.content);
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER posts_search_vector_update
+BEFORE INSERT OR UPDATE OF title, content, tags, search_vector ON posts
+FOR EACH ROW EXECUTE FUNCTION posts_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION comments_search_vector_trigger() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    target bigint;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target := OLD.post_id;
+    ELSE
+        target := NEW.post_id;
+    END IF;
+    UPDATE posts SET search_vector = NULL WHERE id = target;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER comments_search_vector_update
+AFTER INSERT OR UPDATE OF content, deleted OR DELETE ON comments
+FOR EACH ROW EXECUTE FUNCTION comments_search_vector_trigger();
+
+UPDATE posts SET search_vector = NULL;
+
+CREATE index IF NOT EXISTS idx_posts_search_vector ON posts USING gin(search_vector);
@@ -0,0 +1,13 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at timestamptz DEFAULT NULL;
+
+CREATE TABLE IF NOT EXISTS comment_edits (
+    id bigserial PRIMARY KEY,
+    comment_id bigint NOT NULL,
+    editor_id bigint DEFAULT NULL,
+    previous_content text NOT NULL,
+    edited_at timestamptz NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (editor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE index IF NOT EXISTS idx_comment_edits_comment_id ON comment_edits(comment_id, id);
@@ -0,0 +1,4 @@
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS comments_locked,
+    DROP COLUMN IF EXISTS comments_max_depth,
+    DROP COLUMN IF EXISTS comments_followers_only;
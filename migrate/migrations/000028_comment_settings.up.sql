@@ -0,0 +1,4 @@
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS comments_locked boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS comments_max_depth int DEFAULT NULL CHECK (comments_max_depth > 0),
+    ADD COLUMN IF NOT EXISTS comments_followers_only boolean NOT NULL DEFAULT false;
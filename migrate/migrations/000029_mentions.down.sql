@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS mention_notifications;
+DROP TABLE IF EXISTS mentions;
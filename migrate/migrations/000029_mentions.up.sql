@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS mentions (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    author_id bigint NOT NULL,
+    post_id bigint NOT NULL,
+    comment_id bigint DEFAULT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mentions_source_user ON mentions(post_id, (COALESCE(comment_id, 0)), user_id);
+CREATE INDEX IF NOT EXISTS idx_mentions_user_id ON mentions(user_id, id);
+
+-- users already notified of a mention by a post or a comment, kept when the
+-- mention is removed so restoring it does not notify them again
+CREATE TABLE IF NOT EXISTS mention_notifications (
+    user_id bigint NOT NULL,
+    post_id bigint NOT NULL,
+    comment_id bigint DEFAULT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mention_notifications_source_user ON mention_notifications(post_id, (COALESCE(comment_id, 0)), user_id);
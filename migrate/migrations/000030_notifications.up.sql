@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    type varchar(50) NOT NULL,
+    actor_id bigint DEFAULT NULL,
+    post_id bigint DEFAULT NULL,
+    comment_id bigint DEFAULT NULL,
+    read_at timestamptz DEFAULT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- an unread notification is not repeated, e.g. when a vote is removed and cast again
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_unread_event ON notifications(
+    user_id, type, (COALESCE(actor_id, 0)), (COALESCE(post_id, 0)), (COALESCE(comment_id, 0))
+) WHERE read_at IS NULL;
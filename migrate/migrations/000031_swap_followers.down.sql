@@ -0,0 +1,10 @@
+-- swapping the columns back restores the previous orientation of the follows
+CREATE TEMPORARY TABLE followers_swapped AS
+SELECT follower_id AS user_id, user_id AS follower_id, created_at FROM followers;
+
+DELETE FROM followers;
+
+INSERT INTO followers (user_id, follower_id, created_at)
+SELECT user_id, follower_id, created_at FROM followers_swapped;
+
+DROP TABLE followers_swapped;
@@ -0,0 +1,11 @@
+-- follows used to be stored with the followed user in follower_id, swapping
+-- through a copy keeps mutual follows from clashing on the primary key
+CREATE TEMPORARY TABLE followers_swapped AS
+SELECT follower_id AS user_id, user_id AS follower_id, created_at FROM followers;
+
+DELETE FROM followers;
+
+INSERT INTO followers (user_id, follower_id, created_at)
+SELECT user_id, follower_id, created_at FROM followers_swapped;
+
+DROP TABLE followers_swapped;